@@ -0,0 +1,217 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while a CircuitBreaker is open.
+var ErrCircuitOpen = sentinelError("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects every request with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets a single trial request through at a time to check whether the API recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig contains the thresholds of a CircuitBreaker. Zero values are replaced by defaults.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of failures within Window that opens the circuit. Defaults to 5.
+	FailureThreshold int
+	// Window is the duration of the rolling window failures are counted in. Defaults to 1 minute.
+	Window time.Duration
+	// OpenTimeout is how long the circuit stays open before letting trial requests through. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// HalfOpenSuccesses is the number of successful trial requests needed to close the circuit again. Defaults to 1.
+	HalfOpenSuccesses int
+	// OnStateChange, if not nil, is called after every change of state, for example to raise an alert.
+	// It is called synchronously, so it should not block.
+	OnStateChange func(from, to CircuitState)
+}
+
+// CircuitBreaker stops sending requests to an API that keeps failing, so callers fail fast with ErrCircuitOpen
+// instead of waiting for timeouts. Network errors and 5xx responses count as failures.
+// Clients sharing a CircuitBreaker share its state, so they all stop calling the API once it opens.
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mu                sync.Mutex
+	state             CircuitState
+	failures          []time.Time
+	openedAt          time.Time
+	halfOpenSuccesses int
+	trialInFlight     bool
+	// generation is incremented on every change of state, so the outcome of a request admitted in an earlier
+	// state, like a slow request sent before the circuit opened, is not mistaken for the outcome of a trial.
+	generation uint64
+}
+
+// NewCircuitBreaker builds a closed CircuitBreaker from the given configuration.
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenSuccesses <= 0 {
+		config.HalfOpenSuccesses = 1
+	}
+	return &CircuitBreaker{config: config, now: time.Now}
+}
+
+// State returns the current state of the circuit.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Middleware returns the Middleware that guards requests with the circuit breaker.
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			generation, err := b.allow()
+			if err != nil {
+				return nil, err
+			}
+			response, err := next.Do(request)
+			b.record(request.Context(), generation, response, err)
+			return response, err
+		})
+	}
+}
+
+// allow reports whether a request can be sent, moving the circuit to half-open once the open timeout elapsed.
+// It returns the generation of the circuit the request is admitted in, to be given to record.
+func (b *CircuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	from := b.state
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.state = CircuitHalfOpen
+		b.halfOpenSuccesses = 0
+		b.generation++
+	}
+	var err error
+	switch b.state {
+	case CircuitOpen:
+		err = ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.trialInFlight {
+			err = ErrCircuitOpen
+		} else {
+			b.trialInFlight = true
+		}
+	}
+	to, generation := b.state, b.generation
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return generation, err
+}
+
+// record updates the circuit with the outcome of a request admitted in the given generation. Outcomes of requests
+// admitted before the last change of state are ignored.
+func (b *CircuitBreaker) record(ctx context.Context, generation uint64, response *http.Response, err error) {
+	// A request cancelled by its caller says nothing about the health of the API.
+	cancelled := err != nil && ctx.Err() != nil
+	failed := !cancelled && (err != nil || response.StatusCode >= http.StatusInternalServerError)
+
+	b.mu.Lock()
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+	from := b.state
+	now := b.now()
+	switch b.state {
+	case CircuitHalfOpen:
+		b.trialInFlight = false
+		if failed {
+			b.open(now)
+		} else if !cancelled {
+			b.halfOpenSuccesses++
+			if b.halfOpenSuccesses >= b.config.HalfOpenSuccesses {
+				b.state = CircuitClosed
+				b.failures = nil
+				b.generation++
+			}
+		}
+	case CircuitClosed:
+		if failed {
+			b.failures = append(b.failures, now)
+			b.pruneFailures(now)
+			if len(b.failures) >= b.config.FailureThreshold {
+				b.open(now)
+			}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// open moves the circuit to the open state. b.mu must be held.
+func (b *CircuitBreaker) open(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+	b.failures = nil
+	b.generation++
+}
+
+// pruneFailures drops the failures that are out of the rolling window. b.mu must be held.
+func (b *CircuitBreaker) pruneFailures(now time.Time) {
+	kept := b.failures[:0]
+	for _, failure := range b.failures {
+		if now.Sub(failure) < b.config.Window {
+			kept = append(kept, failure)
+		}
+	}
+	b.failures = kept
+}
+
+// notify reports a change of state. It must be called without holding b.mu.
+func (b *CircuitBreaker) notify(from, to CircuitState) {
+	if from == to {
+		return
+	}
+	log.Println("Circuit breaker changed state from", from, "to", to)
+	if b.config.OnStateChange != nil {
+		b.config.OnStateChange(from, to)
+	}
+}
+
+// isCircuitOpen reports whether err was returned because a circuit breaker is open.
+func isCircuitOpen(err error) bool {
+	return errors.Is(err, ErrCircuitOpen)
+}
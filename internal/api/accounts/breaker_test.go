@@ -0,0 +1,167 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newStatusServer answers every request with the status code currently stored in statusCode.
+func newStatusServer(statusCode *int32, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.WriteHeader(int(atomic.LoadInt32(statusCode)))
+	}))
+}
+
+// newTestBreaker returns a circuit breaker with a clock controlled by the returned function.
+func newTestBreaker(config CircuitBreakerConfig) (*CircuitBreaker, func(time.Duration)) {
+	breaker := NewCircuitBreaker(config)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	return breaker, func(elapsed time.Duration) { now = now.Add(elapsed) }
+}
+
+func TestCircuitBreaker_OpensAfterFailureThresholdAndFailsFast(t *testing.T) {
+	statusCode, requests := int32(http.StatusServiceUnavailable), int32(0)
+	server := newStatusServer(&statusCode, &requests)
+	defer server.Close()
+
+	var transitions []CircuitState
+	breaker, _ := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 3, OnStateChange: func(from, to CircuitState) {
+		transitions = append(transitions, to)
+	}})
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: NoRetry, Middlewares: []Middleware{breaker.Middleware()}})
+	if assert.Nil(t, err) {
+		for i := 0; i < 3; i++ {
+			_, err := client.Fetch("some-id")
+			assert.NotErrorIs(t, err, ErrCircuitOpen)
+		}
+		assert.Equal(t, CircuitOpen, breaker.State())
+
+		_, err := client.Fetch("some-id")
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+		assert.Equal(t, []CircuitState{CircuitOpen}, transitions)
+	}
+}
+
+func TestCircuitBreaker_OnlyCountsFailuresInsideWindow(t *testing.T) {
+	statusCode, requests := int32(http.StatusInternalServerError), int32(0)
+	server := newStatusServer(&statusCode, &requests)
+	defer server.Close()
+
+	breaker, advance := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 2, Window: time.Minute})
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: NoRetry, Middlewares: []Middleware{breaker.Middleware()}})
+	if assert.Nil(t, err) {
+		client.Fetch("some-id")
+		advance(2 * time.Minute)
+		client.Fetch("some-id")
+		assert.Equal(t, CircuitClosed, breaker.State())
+		client.Fetch("some-id")
+		assert.Equal(t, CircuitOpen, breaker.State())
+	}
+}
+
+func TestCircuitBreaker_ClosesAfterSuccessfulTrialRequest(t *testing.T) {
+	statusCode, requests := int32(http.StatusBadGateway), int32(0)
+	server := newStatusServer(&statusCode, &requests)
+	defer server.Close()
+
+	var transitions []CircuitState
+	breaker, advance := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: 10 * time.Second,
+		OnStateChange: func(from, to CircuitState) {
+			transitions = append(transitions, to)
+		}})
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: NoRetry, Middlewares: []Middleware{breaker.Middleware()}})
+	if assert.Nil(t, err) {
+		client.Delete("some-id", 0)
+		assert.Equal(t, CircuitOpen, breaker.State())
+
+		advance(10 * time.Second)
+		assert.Equal(t, CircuitHalfOpen, breaker.State())
+		atomic.StoreInt32(&statusCode, http.StatusNoContent)
+		_, err := client.Delete("some-id", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, CircuitClosed, breaker.State())
+		assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}, transitions)
+	}
+}
+
+func TestCircuitBreaker_ReopensAfterFailedTrialRequest(t *testing.T) {
+	statusCode, requests := int32(http.StatusBadGateway), int32(0)
+	server := newStatusServer(&statusCode, &requests)
+	defer server.Close()
+
+	breaker, advance := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: 10 * time.Second})
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: NoRetry, Middlewares: []Middleware{breaker.Middleware()}})
+	if assert.Nil(t, err) {
+		client.Delete("some-id", 0)
+		advance(10 * time.Second)
+		client.Delete("some-id", 0)
+		assert.Equal(t, CircuitOpen, breaker.State())
+		_, err := client.Delete("some-id", 0)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	}
+}
+
+func TestCircuitBreaker_ClientErrorsAreNotFailures(t *testing.T) {
+	statusCode, requests := int32(http.StatusNotFound), int32(0)
+	server := newStatusServer(&statusCode, &requests)
+	defer server.Close()
+
+	breaker, _ := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 1})
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, Middlewares: []Middleware{breaker.Middleware()}})
+	if assert.Nil(t, err) {
+		client.Fetch("some-id")
+		client.Fetch("some-id")
+		assert.Equal(t, CircuitClosed, breaker.State())
+	}
+}
+
+func TestCircuitBreaker_OpenCircuitIsNotRetried(t *testing.T) {
+	statusCode, requests := int32(http.StatusServiceUnavailable), int32(0)
+	server := newStatusServer(&statusCode, &requests)
+	defer server.Close()
+
+	breaker, _ := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 2})
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: fastRetryPolicy, Middlewares: []Middleware{breaker.Middleware()}})
+	if assert.Nil(t, err) {
+		_, err := client.Fetch("some-id")
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	}
+}
+
+func TestCircuitBreaker_IgnoresRequestsSentBeforeTrial(t *testing.T) {
+	breaker, advance := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: 10 * time.Second})
+	ctx := context.Background()
+	success := &http.Response{StatusCode: http.StatusOK}
+
+	// A slow request is sent while the circuit is closed, then another request opens it.
+	slowGeneration, err := breaker.allow()
+	assert.Nil(t, err)
+	failingGeneration, err := breaker.allow()
+	assert.Nil(t, err)
+	breaker.record(ctx, failingGeneration, nil, errors.New("connection refused"))
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	// The trial request is admitted once the open timeout elapsed, then the slow request finishes.
+	advance(10 * time.Second)
+	trialGeneration, err := breaker.allow()
+	assert.Nil(t, err)
+	breaker.record(ctx, slowGeneration, success, nil)
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	_, err = breaker.allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	// Only the outcome of the trial closes the circuit.
+	breaker.record(ctx, trialGeneration, success, nil)
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
@@ -0,0 +1,209 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"github.com/nambroa/interview-accountapi/internal"
+	"github.com/nambroa/interview-accountapi/internal/models/builder"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Config contains the settings used to build an AccountsClient.
+type Config struct {
+	// BaseURL is the scheme and host of the account API, for example "http://fake-api:8080".
+	BaseURL string
+	// HTTPClient is the client used to send requests. If nil, a new client is created.
+	HTTPClient *http.Client
+	// UserAgent is sent in the User-Agent header of every request when not empty.
+	UserAgent string
+	// Timeout limits the time spent on a single request, including reading the response body.
+	// A zero value keeps the timeout of HTTPClient (or no timeout at all if HTTPClient is nil).
+	Timeout time.Duration
+	// MaxConflictRetries is the number of times WithLatestVersion retries after a version conflict.
+	// A zero value means DefaultMaxConflictRetries, a negative value disables retries.
+	MaxConflictRetries int
+	// ConflictBackoff is the delay before the first retry of WithLatestVersion, doubled after every retry.
+	// A zero value means DefaultConflictBackoff.
+	ConflictBackoff time.Duration
+	// RetryPolicy decides which failed requests are retried. If nil, DefaultRetryPolicy is used.
+	// It can be overridden per call with the WithRetryPolicy option.
+	RetryPolicy RetryPolicy
+	// Middlewares wrap every attempt of every request, the first one being the outermost.
+	// For example, a CircuitBreaker or a RateLimiter can be added with its Middleware method. They are safe for
+	// concurrent use, so a single instance can be shared by the middlewares of several clients.
+	Middlewares []Middleware
+	// DecodeOptions changes how the accounts returned by the API are unmarshalled. For example, Lossless keeps
+	// the fields unknown to the models so an account fetched from one environment can be created in another one as is.
+	DecodeOptions builder.DecodeOptions
+}
+
+// Defaults applied by NewAccountsClient to the zero values of Config.
+const (
+	DefaultMaxConflictRetries = 3
+	DefaultConflictBackoff    = 100 * time.Millisecond
+)
+
+// DefaultConfig returns the configuration used by the package level functions, which targets the fake API.
+func DefaultConfig() Config {
+	return Config{BaseURL: internal.BaseURL}
+}
+
+// AccountsClient talks to the accounts resource of a single account API instance.
+// It is safe for concurrent use by multiple goroutines.
+type AccountsClient struct {
+	baseURL            *url.URL
+	accountsURL        string
+	httpClient         *http.Client
+	doer               Doer
+	userAgent          string
+	maxConflictRetries int
+	conflictBackoff    time.Duration
+	retryPolicy        RetryPolicy
+	decodeOptions      builder.DecodeOptions
+}
+
+// DefaultClient is the client used by the package level Create, Fetch and Delete functions.
+var DefaultClient = mustNewAccountsClient(DefaultConfig())
+
+// NewAccountsClient builds an AccountsClient from the given configuration.
+func NewAccountsClient(config Config) (*AccountsClient, error) {
+	if config.BaseURL == "" {
+		return nil, errors.New("accounts: base URL is required")
+	}
+	baseURL, err := url.Parse(config.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, errors.New("accounts: base URL must be absolute: " + config.BaseURL)
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	if config.Timeout > 0 {
+		// Copy the client so the caller's client is not modified.
+		clientCopy := *httpClient
+		clientCopy.Timeout = config.Timeout
+		httpClient = &clientCopy
+	}
+
+	maxConflictRetries := config.MaxConflictRetries
+	if maxConflictRetries == 0 {
+		maxConflictRetries = DefaultMaxConflictRetries
+	} else if maxConflictRetries < 0 {
+		maxConflictRetries = 0
+	}
+	conflictBackoff := config.ConflictBackoff
+	if conflictBackoff <= 0 {
+		conflictBackoff = DefaultConflictBackoff
+	}
+	retryPolicy := config.RetryPolicy
+	if retryPolicy == nil {
+		retryPolicy = DefaultRetryPolicy()
+	}
+
+	return &AccountsClient{
+		baseURL:            baseURL,
+		accountsURL:        strings.TrimSuffix(baseURL.String(), "/") + internal.V1API + internal.AccountPrefix,
+		httpClient:         httpClient,
+		doer:               chain(httpClient, config.Middlewares),
+		userAgent:          config.UserAgent,
+		maxConflictRetries: maxConflictRetries,
+		conflictBackoff:    conflictBackoff,
+		retryPolicy:        retryPolicy,
+		decodeOptions:      config.DecodeOptions,
+	}, nil
+}
+
+func mustNewAccountsClient(config Config) *AccountsClient {
+	client, err := NewAccountsClient(config)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// accountURL returns the URL of a single account, escaping its ID.
+func (c *AccountsClient) accountURL(accountID string) string {
+	return c.accountsURL + "/" + url.PathEscape(accountID)
+}
+
+// newRequest builds a request of the given operation against the accounts API, with the headers shared by every operation.
+func (c *AccountsClient) newRequest(ctx context.Context, operation Operation, method, url string, body io.Reader) (*http.Request, error) {
+	ctx = context.WithValue(ctx, operationContextKey{}, operation)
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if c.userAgent != "" {
+		request.Header.Set("User-Agent", c.userAgent)
+	}
+	return request, nil
+}
+
+// do sends the request and reads the whole response body, closing it afterwards. Failed attempts are retried
+// according to the retry policy of the call, except POST and PATCH requests that are not marked as idempotent.
+// If the request failed because its context was cancelled or its deadline exceeded, the context error
+// (context.Canceled or context.DeadlineExceeded) is returned as is, so callers can tell it apart from API errors.
+// If it failed without a response after several attempts, the error is a *RetryError.
+func (c *AccountsClient) do(request *http.Request, options *requestOptions) (*http.Response, []byte, error) {
+	retryPolicy := c.retryPolicy
+	if options.retryPolicy != nil {
+		retryPolicy = options.retryPolicy
+	}
+	retryable := (request.Method != http.MethodPost && request.Method != http.MethodPatch) || options.idempotent
+
+	for attempt := 1; ; attempt++ {
+		attemptRequest, err := newAttempt(request, attempt)
+		if err != nil {
+			return nil, nil, err
+		}
+		response, body, err := c.send(attemptRequest)
+		if request.Context().Err() != nil || !retryable {
+			return response, body, err
+		}
+		delay, retry := retryPolicy.Retry(attempt, attemptRequest, response, err)
+		if !retry {
+			if err != nil && attempt > 1 {
+				err = &RetryError{Attempts: attempt, Err: err}
+			}
+			return response, body, err
+		}
+		log.Println("Retrying request after failed attempt", attempt, "in", delay)
+		if err := wait(request.Context(), delay); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// send sends a single attempt of a request and reads the whole response body, closing it afterwards.
+func (c *AccountsClient) send(request *http.Request) (*http.Response, []byte, error) {
+	response, err := c.doer.Do(request)
+	if err != nil {
+		return nil, nil, contextError(request.Context(), err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return response, nil, contextError(request.Context(), err)
+	}
+	return response, body, nil
+}
+
+// contextError replaces err with the context error when ctx is done.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
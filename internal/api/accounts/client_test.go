@@ -0,0 +1,97 @@
+package accounts
+
+import (
+	"context"
+	"github.com/nambroa/interview-accountapi/internal"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewAccountsClient_WithoutBaseURLReturnsError(t *testing.T) {
+	client, err := NewAccountsClient(Config{})
+	assert.NotNil(t, err)
+	assert.Nil(t, client)
+}
+
+func TestNewAccountsClient_WithRelativeBaseURLReturnsError(t *testing.T) {
+	client, err := NewAccountsClient(Config{BaseURL: "fake-api:8080"})
+	assert.NotNil(t, err)
+	assert.Nil(t, client)
+}
+
+func TestNewAccountsClient_TimeoutDoesNotModifyGivenHTTPClient(t *testing.T) {
+	httpClient := &http.Client{}
+	client, err := NewAccountsClient(Config{BaseURL: internal.BaseURL, HTTPClient: httpClient, Timeout: time.Second})
+	if assert.Nil(t, err) {
+		assert.Equal(t, time.Duration(0), httpClient.Timeout)
+		assert.Equal(t, time.Second, client.httpClient.Timeout)
+	}
+}
+
+func TestAccountsClient_SendsRequestsToConfiguredBaseURL(t *testing.T) {
+	var requestedPath, userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+		userAgent = r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL + "/", UserAgent: "accounts-test"})
+	if assert.Nil(t, err) {
+		response, err := client.Delete("some-id", 0)
+		assert.Nil(t, err)
+		if assert.NotNil(t, response) {
+			assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		}
+		assert.Equal(t, internal.V1API+internal.AccountPrefix+"/some-id", requestedPath)
+		assert.Equal(t, "accounts-test", userAgent)
+	}
+}
+
+func TestAccountsClient_CancelledContextReturnsContextError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		account, err := client.FetchContext(ctx, "some-id")
+		assert.Nil(t, account)
+		assert.Equal(t, context.Canceled, err)
+	}
+}
+
+func TestAccountsClient_ExceededDeadlineReturnsContextError(t *testing.T) {
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Simulate a hung API.
+		select {
+		case <-blocked:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(blocked)
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		response, err := client.DeleteContext(ctx, "some-id", 0)
+		assert.Nil(t, response)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	}
+}
+
+// newTestAccount builds a valid account to be served by test servers.
+func newTestAccount() (*models.Account, error) {
+	return internal.DefaultAccountBuilder().Build()
+}
@@ -0,0 +1,44 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"log"
+)
+
+// WithLatestVersion runs a read-modify-write flow on an account of the fake API, using the DefaultClient.
+func WithLatestVersion(ctx context.Context, accountID string, apply func(*models.Account) error) error {
+	return DefaultClient.WithLatestVersion(ctx, accountID, apply)
+}
+
+// WithLatestVersion fetches the account and calls apply with it, so apply can update or delete the account
+// using its current version. If apply fails with a conflict (errors.Is(err, ErrConflict)), because the account
+// was modified in the meantime, the account is fetched again and apply is retried, waiting an exponentially
+// growing delay between attempts, up to the MaxConflictRetries of the client.
+// It returns the error of the last call to apply, or the error of the fetch if it failed.
+//
+//	err := client.WithLatestVersion(ctx, accountID, func(account *models.Account) error {
+//		_, err := client.DeleteAccountContext(ctx, account)
+//		return err
+//	})
+func (c *AccountsClient) WithLatestVersion(ctx context.Context, accountID string, apply func(*models.Account) error) error {
+	backoff := c.conflictBackoff
+	for retry := 0; ; retry++ {
+		account, err := c.FetchContext(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		err = apply(account)
+		if err == nil || !errors.Is(err, ErrConflict) || retry >= c.maxConflictRetries {
+			return err
+		}
+		log.Println("Version conflict found, retrying with latest version of account:", accountID)
+
+		// Wait before fetching the account again.
+		if err := wait(ctx, backoff); err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
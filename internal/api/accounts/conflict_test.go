@@ -0,0 +1,136 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"github.com/nambroa/interview-accountapi/internal/models/builder"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newConflictingServer serves an account that is modified by someone else before each of the first conflicts
+// PATCH requests, so those requests fail with a version conflict.
+func newConflictingServer(account *models.Account, conflicts int32, fetches *int32) *httptest.Server {
+	var version int64
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			atomic.AddInt32(fetches, 1)
+			currentVersion := version
+			account.Data.Version = &currentVersion
+			json.NewEncoder(w).Encode(account)
+		case http.MethodPatch:
+			if conflicts > 0 {
+				conflicts--
+				version++
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			json.NewEncoder(w).Encode(account)
+		}
+	}))
+}
+
+func updateSecondaryIdentification(client *AccountsClient) func(*models.Account) error {
+	return func(account *models.Account) error {
+		patch, err := builder.NewAccountPatchBuilder(account.Data.ID, *account.Data.Version).
+			WithSecondaryIdentification("Alfred").Build()
+		if err != nil {
+			return err
+		}
+		_, err = client.Update(patch)
+		return err
+	}
+}
+
+func TestWithLatestVersion_RetriesAfterConflict(t *testing.T) {
+	account, err := newTestAccount()
+	if assert.Nil(t, err) {
+		var fetches int32
+		server := newConflictingServer(account, 2, &fetches)
+		defer server.Close()
+		client, err := NewAccountsClient(Config{BaseURL: server.URL, ConflictBackoff: time.Millisecond})
+		if assert.Nil(t, err) {
+			err := client.WithLatestVersion(context.Background(), account.Data.ID, updateSecondaryIdentification(client))
+			assert.Nil(t, err)
+			assert.Equal(t, int32(3), atomic.LoadInt32(&fetches))
+		}
+	}
+}
+
+func TestWithLatestVersion_StopsAfterMaxConflictRetries(t *testing.T) {
+	account, err := newTestAccount()
+	if assert.Nil(t, err) {
+		var fetches int32
+		server := newConflictingServer(account, 10, &fetches)
+		defer server.Close()
+		client, err := NewAccountsClient(Config{BaseURL: server.URL, MaxConflictRetries: 2, ConflictBackoff: time.Millisecond})
+		if assert.Nil(t, err) {
+			err := client.WithLatestVersion(context.Background(), account.Data.ID, updateSecondaryIdentification(client))
+			assert.ErrorIs(t, err, ErrConflict)
+			assert.Equal(t, int32(3), atomic.LoadInt32(&fetches))
+		}
+	}
+}
+
+func TestWithLatestVersion_DoesNotRetryOtherErrors(t *testing.T) {
+	account, err := newTestAccount()
+	if assert.Nil(t, err) {
+		var fetches int32
+		server := newConflictingServer(account, 0, &fetches)
+		defer server.Close()
+		client, err := NewAccountsClient(Config{BaseURL: server.URL, ConflictBackoff: time.Millisecond})
+		if assert.Nil(t, err) {
+			err := client.WithLatestVersion(context.Background(), account.Data.ID, func(*models.Account) error {
+				return ErrBadRequest
+			})
+			assert.Equal(t, ErrBadRequest, err)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+		}
+	}
+}
+
+func TestWithLatestVersion_StopsWhenContextIsDoneWhileWaiting(t *testing.T) {
+	account, err := newTestAccount()
+	if assert.Nil(t, err) {
+		var fetches int32
+		server := newConflictingServer(account, 10, &fetches)
+		defer server.Close()
+		client, err := NewAccountsClient(Config{BaseURL: server.URL, ConflictBackoff: time.Hour})
+		if assert.Nil(t, err) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			err := client.WithLatestVersion(ctx, account.Data.ID, updateSecondaryIdentification(client))
+			assert.Equal(t, context.DeadlineExceeded, err)
+		}
+	}
+}
+
+func TestWithLatestVersion_DeletesAccountStoredUnderEarlierRules(t *testing.T) {
+	// GB accounts numbers are now 8 digits long, but the API holds accounts created before that rule.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"data":{"attributes":{"account_number":"1234567890","bank_id":"400300","bank_id_code":"GBDSC","base_currency":"GBP",
+				"bic":"NWBKGB22","country":"GB","name":["Batman"]},"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+				"organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c","type":"accounts","version":0}}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		err = client.WithLatestVersion(context.Background(), "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", func(account *models.Account) error {
+			assert.Equal(t, "1234567890", account.Data.Attributes.AccountNumber)
+			_, err := client.DeleteAccount(account)
+			return err
+		})
+		assert.Nil(t, err)
+	}
+}
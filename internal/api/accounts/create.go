@@ -2,41 +2,174 @@ package accounts
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
-	"github.com/nambroa/interview-accountapi/internal"
 	"github.com/nambroa/interview-accountapi/internal/models"
-	"io"
+	uuid "github.com/nu7hatch/gouuid"
 	"log"
 	"net/http"
+	"reflect"
 )
 
-// Create sends an account payload to the fake API to create an account. It returns its associated response and error data.
-func Create(payload *models.Account) (*http.Response, error) {
+// IdempotencyKeyHeader is the request header holding the idempotency key of a Create.
+const IdempotencyKeyHeader = "Idempotency-Key"
 
+// Create sends an account payload to the fake API to create an account, using the DefaultClient.
+// It returns its associated response and error data.
+func Create(payload *models.Account, options ...RequestOption) (*http.Response, error) {
+	return DefaultClient.Create(payload, options...)
+}
+
+// CreateContext is like Create but uses ctx for the request.
+func CreateContext(ctx context.Context, payload *models.Account, options ...RequestOption) (*http.Response, error) {
+	return DefaultClient.CreateContext(ctx, payload, options...)
+}
+
+// CreateAccount creates an account in the fake API and returns it as created, using the DefaultClient.
+func CreateAccount(payload *models.Account, options ...RequestOption) (*models.Account, error) {
+	return DefaultClient.CreateAccount(payload, options...)
+}
+
+// CreateAccountContext is like CreateAccount but uses ctx for the request.
+func CreateAccountContext(ctx context.Context, payload *models.Account, options ...RequestOption) (*models.Account, error) {
+	return DefaultClient.CreateAccountContext(ctx, payload, options...)
+}
+
+// Create sends an account payload to the API to create an account. It returns its associated response and error data.
+// The body of the response has already been read and closed: use CreateAccount to get the created account.
+// If the API does not answer with 201 Created, the error is an *APIError.
+// Failed creations are only retried when the Idempotent option is given.
+//
+// Every call sends an idempotency key in the IdempotencyKeyHeader, generated once per call and shared by its retries,
+// unless one is given with the WithIdempotencyKey option. If the API answers with 409 Conflict because an account
+// with the same ID already exists, the existing account is fetched: when it matches the payload, the creation is
+// considered replayed and the conflict response is returned with a nil error.
+func (c *AccountsClient) Create(payload *models.Account, options ...RequestOption) (*http.Response, error) {
+	return c.CreateContext(context.Background(), payload, options...)
+}
+
+// CreateContext is like Create but uses ctx for the request. If ctx is cancelled or its deadline is exceeded
+// before the response is read, ctx.Err() is returned.
+func (c *AccountsClient) CreateContext(ctx context.Context, payload *models.Account, options ...RequestOption) (*http.Response, error) {
+	response, _, _, err := c.create(ctx, payload, newRequestOptions(options))
+	return response, err
+}
+
+// CreateAccount is like Create but returns the created account as sent back by the API, including the fields
+// populated by the API such as its version. When the creation is a replay, the existing account is returned.
+func (c *AccountsClient) CreateAccount(payload *models.Account, options ...RequestOption) (*models.Account, error) {
+	return c.CreateAccountContext(context.Background(), payload, options...)
+}
+
+// CreateAccountContext is like CreateAccount but uses ctx for the request.
+func (c *AccountsClient) CreateAccountContext(ctx context.Context, payload *models.Account, options ...RequestOption) (*models.Account, error) {
+	_, accountJSON, replayedAccount, err := c.create(ctx, payload, newRequestOptions(options))
+	if err != nil {
+		return nil, err
+	}
+	if replayedAccount != nil {
+		return replayedAccount, nil
+	}
+	return c.decodeAccount(accountJSON)
+}
+
+// create sends the creation request and returns its response and body. When the creation is a replay,
+// the existing account is returned as well.
+func (c *AccountsClient) create(ctx context.Context, payload *models.Account, requestOptions *requestOptions) (*http.Response, []byte, *models.Account, error) {
 	// Convert account data to json
 	marshalledAccount, err := json.Marshal(payload)
 	if err != nil {
 		log.Println("Error marhsalling account data:", err)
-		return nil, err
+		return nil, nil, nil, err
+	}
+	// Build request
+	request, err := c.newRequest(ctx, OperationCreate, http.MethodPost, c.accountsURL, bytes.NewReader(marshalledAccount))
+	if err != nil {
+		log.Println("Error found while building create account request:", err)
+		return nil, nil, nil, err
 	}
+	idempotencyKey := requestOptions.idempotencyKey
+	if idempotencyKey == "" {
+		generatedKey, err := uuid.NewV4()
+		if err != nil {
+			log.Println("Error found while generating idempotency key:", err)
+			return nil, nil, nil, err
+		}
+		idempotencyKey = generatedKey.String()
+	}
+	request.Header.Set(IdempotencyKeyHeader, idempotencyKey)
 	// Create account
-	response, err := http.Post(internal.AccountURL, "application/json", bytes.NewReader(marshalledAccount))
+	response, body, err := c.do(request, requestOptions)
 
 	// Process response
 	if err != nil {
 		log.Println("Error found while creating account:", err)
+		return nil, nil, nil, err
+	}
+	if response.StatusCode == http.StatusConflict {
+		if replayedAccount := c.replayedAccount(ctx, payload); replayedAccount != nil {
+			log.Println("Account already created with the same data, treating conflict as replay:", payload.Data.ID)
+			return response, body, replayedAccount, nil
+		}
+	}
+	if response.StatusCode != http.StatusCreated {
+		log.Println("Response returned error status code:", response.StatusCode)
+		return response, body, nil, newAPIError(response, body)
+	}
+	return response, body, nil, nil
+}
+
+// replayedAccount returns the existing account the payload describes if it has the same data,
+// meaning an earlier attempt of the same creation succeeded. Otherwise, it returns nil.
+func (c *AccountsClient) replayedAccount(ctx context.Context, payload *models.Account) *models.Account {
+	if payload == nil || payload.Data == nil || payload.Data.ID == "" {
+		return nil
+	}
+	existingAccount, err := c.FetchContext(ctx, payload.Data.ID)
+	if err != nil {
+		log.Println("Error found while fetching conflicting account:", err)
+		return nil
+	}
+
+	// The version is ignored, as the API sets it. Fields only set by the API are ignored as well.
+	payloadData, existingData := *payload.Data, *existingAccount.Data
+	payloadData.Version, existingData.Version = nil, nil
+	wanted, err := toJSONValue(payloadData)
+	if err != nil {
+		return nil
+	}
+	existing, err := toJSONValue(existingData)
+	if err != nil || !containsJSONValue(existing, wanted) {
+		return nil
+	}
+	return existingAccount
+}
+
+// toJSONValue converts a value to its generic JSON representation.
+func toJSONValue(value interface{}) (interface{}, error) {
+	marshalledValue, err := json.Marshal(value)
+	if err != nil {
 		return nil, err
-	} else {
-		defer response.Body.Close()
-		body, _ := io.ReadAll(response.Body)
-		if response.StatusCode != http.StatusCreated {
-			log.Println("Response returned error status code:", response.StatusCode)
-			// Read body and return it in the response as error.
-			return response, errors.New(fmt.Sprintf("Status code: %d. Body: %s", response.StatusCode, string(body)))
-		} else {
-			return response, nil
+	}
+	var jsonValue interface{}
+	err = json.Unmarshal(marshalledValue, &jsonValue)
+	return jsonValue, err
+}
+
+// containsJSONValue reports whether got contains every field of wanted with the same value, recursively.
+func containsJSONValue(got, wanted interface{}) bool {
+	wantedObject, ok := wanted.(map[string]interface{})
+	if !ok {
+		return reflect.DeepEqual(got, wanted)
+	}
+	gotObject, ok := got.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for key, wantedValue := range wantedObject {
+		if !containsJSONValue(gotObject[key], wantedValue) {
+			return false
 		}
 	}
+	return true
 }
@@ -1,11 +1,17 @@
 package accounts
 
 import (
+	"encoding/json"
 	"github.com/nambroa/interview-accountapi/internal"
 	"github.com/nambroa/interview-accountapi/internal/models"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestCreate_ValidAccount(t *testing.T) {
@@ -33,12 +39,12 @@ func TestCreate_FullAccount(t *testing.T) {
 	var version int64 = 1
 
 	accountBuilder.WithAlternativeNames(names)
-	accountBuilder.WithIban("AB12AZ33")
+	accountBuilder.WithIban("GB33BUKB20201555555555")
 	accountBuilder.WithSecondaryIdentification("Alfred")
 	accountBuilder.WithJointAccount(&jointAcc)
 	accountBuilder.WithAccountClassification(&accClass)
 	accountBuilder.WithBaseCurrency("ARS")
-	accountBuilder.WithAccountNumber("1234567890")
+	accountBuilder.WithAccountNumber("12345678")
 	accountBuilder.WithNameMatchingStatus(&NMS)
 	accountBuilder.WithVersion(&version)
 
@@ -52,18 +58,194 @@ func TestCreate_FullAccount(t *testing.T) {
 	}
 }
 
-// Since IBAN does not have validators, I can create invalid accounts to showcase tests where the API returns bad request.
-// In the future I would like to learn how the IBAN number is composed and create a custom validator using the library to validate IBAN (possibly with a regex).
+func TestCreateAccount_ReturnsCreatedAccount(t *testing.T) {
+	var accountBuilder = internal.DefaultAccountBuilder()
+	account, err := accountBuilder.Build()
+	if assert.Nil(t, err) {
+		createdAccount, err := CreateAccount(account)
+		assert.Nil(t, err)
+		if assert.NotNil(t, createdAccount) {
+			assert.Equal(t, account.Data.ID, createdAccount.Data.ID)
+			assert.NotNil(t, createdAccount.Data.Version)
+		}
+	}
+}
 
+// Since the builder validates IBANs, the IBAN is changed after building the account to showcase tests where the API returns bad request.
 func TestCreate_AccountWithInvalidIBANReturnsBadRequest(t *testing.T) {
 	var accountBuilder = internal.DefaultAccountBuilder()
-	accountBuilder.WithIban("$#*$*(@*($@*#$*&!!!!!!!!!!!!!!!!!%%^^#$!!!!!!!!!!!!!!!!!!")
 	account, err := accountBuilder.Build()
 	if assert.Nil(t, err) {
+		account.Data.Attributes.Iban = "$#*$*(@*($@*#$*&!!!!!!!!!!!!!!!!!%%^^#$!!!!!!!!!!!!!!!!!!"
 		response, err := Create(account)
-		assert.NotNil(t, err)
+		assert.ErrorIs(t, err, ErrBadRequest)
 		if assert.NotNil(t, response) {
 			assert.Equal(t, response.StatusCode, http.StatusBadRequest)
 		}
 	}
 }
+
+// newAccountStoreServer stores created accounts and serves them back. The first failedCreates creations are
+// stored but answered with 500, as if the response had been lost.
+func newAccountStoreServer(failedCreates int, idempotencyKeys *[]string) *httptest.Server {
+	var mu sync.Mutex
+	stored := map[string][]byte{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPost:
+			*idempotencyKeys = append(*idempotencyKeys, r.Header.Get(IdempotencyKeyHeader))
+			body, _ := io.ReadAll(r.Body)
+			var account models.Account
+			json.Unmarshal(body, &account)
+			if _, ok := stored[account.Data.ID]; ok {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			stored[account.Data.ID] = body
+			if failedCreates > 0 {
+				failedCreates--
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write(body)
+		case http.MethodGet:
+			body, ok := stored[path.Base(r.URL.Path)]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(body)
+		}
+	}))
+}
+
+func TestCreate_SendsIdempotencyKeySharedByRetries(t *testing.T) {
+	var idempotencyKeys []string
+	server := newAccountStoreServer(1, &idempotencyKeys)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: fastRetryPolicy})
+	if assert.Nil(t, err) {
+		account, err := internal.DefaultAccountBuilder().Build()
+		if assert.Nil(t, err) {
+			// The lost response is retried and the conflict is recognised as a replay.
+			response, err := client.Create(account, Idempotent())
+			assert.Nil(t, err)
+			if assert.NotNil(t, response) {
+				assert.Equal(t, http.StatusConflict, response.StatusCode)
+			}
+			if assert.Len(t, idempotencyKeys, 2) {
+				assert.NotEmpty(t, idempotencyKeys[0])
+				assert.Equal(t, idempotencyKeys[0], idempotencyKeys[1])
+			}
+		}
+
+		otherAccount, err := internal.DefaultAccountBuilder().Build()
+		if assert.Nil(t, err) {
+			_, err := client.Create(otherAccount)
+			assert.Nil(t, err)
+			if assert.Len(t, idempotencyKeys, 3) {
+				assert.NotEqual(t, idempotencyKeys[0], idempotencyKeys[2])
+			}
+		}
+	}
+}
+
+func TestCreate_WithIdempotencyKeySendsGivenKey(t *testing.T) {
+	var idempotencyKeys []string
+	server := newAccountStoreServer(0, &idempotencyKeys)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		account, err := internal.DefaultAccountBuilder().Build()
+		if assert.Nil(t, err) {
+			_, err := client.Create(account, WithIdempotencyKey("my-key"))
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"my-key"}, idempotencyKeys)
+		}
+	}
+}
+
+func TestCreate_ConflictWithDifferentAccountReturnsConflict(t *testing.T) {
+	var idempotencyKeys []string
+	server := newAccountStoreServer(0, &idempotencyKeys)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		account, err := internal.DefaultAccountBuilder().Build()
+		if assert.Nil(t, err) {
+			_, err := client.Create(account)
+			assert.Nil(t, err)
+
+			// Same ID, different data.
+			account.Data.Attributes.SecondaryIdentification = "Alfred"
+			response, err := client.Create(account)
+			assert.ErrorIs(t, err, ErrConflict)
+			if assert.NotNil(t, response) {
+				assert.Equal(t, http.StatusConflict, response.StatusCode)
+			}
+		}
+	}
+}
+
+func TestCreateAccount_DecodesAccountSentBackByAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var account models.Account
+		json.NewDecoder(r.Body).Decode(&account)
+		// The API populates the version, the status, the timestamps and the links.
+		version, status := int64(0), models.CONFIRMED
+		createdOn, modifiedOn := time.Date(2022, 11, 2, 10, 0, 0, 0, time.UTC), time.Date(2022, 11, 2, 10, 0, 1, 0, time.UTC)
+		account.Data.Version = &version
+		account.Data.Attributes.Status = &status
+		account.Data.CreatedOn, account.Data.ModifiedOn = &createdOn, &modifiedOn
+		account.Links = &models.Links{Self: "/v1/organisation/accounts/" + account.Data.ID}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(account)
+	}))
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		account, err := internal.DefaultAccountBuilder().Build()
+		if assert.Nil(t, err) {
+			account.Data.Version = nil
+			createdAccount, err := client.CreateAccount(account)
+			assert.Nil(t, err)
+			if assert.NotNil(t, createdAccount) {
+				assert.Equal(t, account.Data.ID, createdAccount.Data.ID)
+				assert.Equal(t, int64(0), *createdAccount.Data.Version)
+				assert.Equal(t, models.CONFIRMED, *createdAccount.Data.Attributes.Status)
+				if assert.NotNil(t, createdAccount.Data.CreatedOn) && assert.NotNil(t, createdAccount.Data.ModifiedOn) {
+					assert.Equal(t, time.Date(2022, 11, 2, 10, 0, 0, 0, time.UTC), createdAccount.Data.CreatedOn.UTC())
+					assert.Equal(t, time.Date(2022, 11, 2, 10, 0, 1, 0, time.UTC), createdAccount.Data.ModifiedOn.UTC())
+				}
+				if assert.NotNil(t, createdAccount.Links) {
+					assert.Equal(t, "/v1/organisation/accounts/"+account.Data.ID, createdAccount.Links.Self)
+				}
+			}
+		}
+	}
+}
+
+func TestCreateAccount_ReplayReturnsExistingAccount(t *testing.T) {
+	var idempotencyKeys []string
+	server := newAccountStoreServer(1, &idempotencyKeys)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: fastRetryPolicy})
+	if assert.Nil(t, err) {
+		account, err := internal.DefaultAccountBuilder().Build()
+		if assert.Nil(t, err) {
+			createdAccount, err := client.CreateAccount(account, Idempotent())
+			assert.Nil(t, err)
+			if assert.NotNil(t, createdAccount) {
+				assert.Equal(t, account.Data.ID, createdAccount.Data.ID)
+			}
+		}
+	}
+}
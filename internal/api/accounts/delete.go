@@ -1,35 +1,85 @@
 package accounts
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"github.com/nambroa/interview-accountapi/internal"
+	"github.com/nambroa/interview-accountapi/internal/models"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 )
 
-// Delete deletes an account based on its ID and Version.
-func Delete(accountID string, version string) (*http.Response, error) {
-	var deleteAccountURL = internal.AccountURL + "/" + accountID + "?version=" + version
+// Delete deletes an account based on its ID and Version, using the DefaultClient.
+func Delete(accountID string, version int64, options ...RequestOption) (*http.Response, error) {
+	return DefaultClient.Delete(accountID, version, options...)
+}
+
+// DeleteContext is like Delete but uses ctx for the request.
+func DeleteContext(ctx context.Context, accountID string, version int64, options ...RequestOption) (*http.Response, error) {
+	return DefaultClient.DeleteContext(ctx, accountID, version, options...)
+}
+
+// DeleteAccount deletes an account using its own ID and Version, using the DefaultClient.
+func DeleteAccount(account *models.Account, options ...RequestOption) (*http.Response, error) {
+	return DefaultClient.DeleteAccount(account, options...)
+}
+
+// DeleteAccountContext is like DeleteAccount but uses ctx for the request.
+func DeleteAccountContext(ctx context.Context, account *models.Account, options ...RequestOption) (*http.Response, error) {
+	return DefaultClient.DeleteAccountContext(ctx, account, options...)
+}
+
+// Delete deletes an account based on its ID and Version. If the version is not the current one,
+// the error is a *VersionMismatchError. For any other status code than 204 No Content, the error is an *APIError,
+// unless the IgnoreNotFound option is given and the account does not exist.
+func (c *AccountsClient) Delete(accountID string, version int64, options ...RequestOption) (*http.Response, error) {
+	return c.DeleteContext(context.Background(), accountID, version, options...)
+}
+
+// DeleteContext is like Delete but uses ctx for the request. If ctx is cancelled or its deadline is exceeded
+// before the response is read, ctx.Err() is returned.
+func (c *AccountsClient) DeleteContext(ctx context.Context, accountID string, version int64, options ...RequestOption) (*http.Response, error) {
+	requestOptions := newRequestOptions(options)
+	var deleteAccountURL = c.accountURL(accountID) + "?" + url.Values{"version": {strconv.FormatInt(version, 10)}}.Encode()
 
-	// Delete account
-	httpClient := &http.Client{}
 	// Build request
-	request, err := http.NewRequest(http.MethodDelete, deleteAccountURL, nil)
+	request, err := c.newRequest(ctx, OperationDelete, http.MethodDelete, deleteAccountURL, nil)
 	if err != nil {
 		log.Println("Error found while building delete account request:", err)
 		return nil, err
 	}
-	response, err := httpClient.Do(request)
+	// Delete account
+	response, body, err := c.do(request, requestOptions)
 	if err != nil {
 		log.Println("Error found while deleting account:", err)
 		return nil, err
 	}
 
 	// Process response
+	if response.StatusCode == http.StatusNotFound && requestOptions.ignoreNotFound {
+		return response, nil
+	}
+	if response.StatusCode == http.StatusConflict {
+		log.Println("Response returned version conflict for account:", accountID)
+		return response, &VersionMismatchError{AccountID: accountID, Version: version, Err: newAPIError(response, body)}
+	}
 	if response.StatusCode != http.StatusNoContent {
 		log.Println("Response returned error status code:", response.StatusCode)
-		return response, errors.New(fmt.Sprintf("Status code: %d", response.StatusCode))
+		return response, newAPIError(response, body)
 	}
 	return response, nil
 }
+
+// DeleteAccount deletes an account using its own ID and Version, as returned by Fetch.
+func (c *AccountsClient) DeleteAccount(account *models.Account, options ...RequestOption) (*http.Response, error) {
+	return c.DeleteAccountContext(context.Background(), account, options...)
+}
+
+// DeleteAccountContext is like DeleteAccount but uses ctx for the request.
+func (c *AccountsClient) DeleteAccountContext(ctx context.Context, account *models.Account, options ...RequestOption) (*http.Response, error) {
+	if account == nil || account.Data == nil || account.Data.Version == nil {
+		return nil, errors.New("accounts: account must contain its ID and version")
+	}
+	return c.DeleteContext(ctx, account.Data.ID, *account.Data.Version, options...)
+}
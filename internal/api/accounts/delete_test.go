@@ -2,10 +2,11 @@ package accounts
 
 import (
 	"github.com/nambroa/interview-accountapi/internal"
+	"github.com/nambroa/interview-accountapi/internal/models"
 	uuid "github.com/nu7hatch/gouuid"
 	"github.com/stretchr/testify/assert"
 	"net/http"
-	"strconv"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -17,7 +18,7 @@ func TestDelete_ValidCreatedAccount(t *testing.T) {
 		_, err := Create(acc)
 		if assert.Nil(t, err) {
 			// Delete account
-			response, err := Delete(acc.Data.ID, strconv.FormatInt(*acc.Data.Version, 10))
+			response, err := DeleteAccount(acc)
 			assert.Nil(t, err)
 			if assert.NotNil(t, response) {
 				assert.Equal(t, response.StatusCode, http.StatusNoContent)
@@ -28,8 +29,8 @@ func TestDelete_ValidCreatedAccount(t *testing.T) {
 
 func TestDelete_WithNonExistentIDReturnsNotFound(t *testing.T) {
 	fakeID, _ := uuid.NewV4()
-	response, err := Delete(fakeID.String(), "0")
-	assert.NotNil(t, err)
+	response, err := Delete(fakeID.String(), 0)
+	assert.ErrorIs(t, err, ErrNotFound)
 	if assert.NotNil(t, response) {
 		assert.Equal(t, response.StatusCode, http.StatusNotFound)
 	}
@@ -44,8 +45,8 @@ func TestDelete_WithNonExistentVersionReturnsConflict(t *testing.T) {
 		_, err := Create(acc)
 		if assert.Nil(t, err) {
 			// Delete account
-			response, err := Delete(acc.Data.ID, "3222423")
-			assert.NotNil(t, err)
+			response, err := Delete(acc.Data.ID, 3222423)
+			assert.ErrorIs(t, err, ErrConflict)
 			if assert.NotNil(t, response) {
 				assert.Equal(t, response.StatusCode, http.StatusConflict)
 
@@ -53,3 +54,34 @@ func TestDelete_WithNonExistentVersionReturnsConflict(t *testing.T) {
 		}
 	}
 }
+
+func TestDelete_WithNonExistentIDAndIgnoreNotFoundSucceeds(t *testing.T) {
+	fakeID, _ := uuid.NewV4()
+	response, err := Delete(fakeID.String(), 0, IgnoreNotFound())
+	assert.Nil(t, err)
+	if assert.NotNil(t, response) {
+		assert.Equal(t, response.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestDelete_EscapesAccountIDAndEncodesVersion(t *testing.T) {
+	var requestURI string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		_, err := client.Delete("some/id?version=1", 7)
+		assert.Nil(t, err)
+		assert.Equal(t, "/v1/organisation/accounts/some%2Fid%3Fversion=1?version=7", requestURI)
+	}
+}
+
+func TestDeleteAccount_WithoutVersionReturnsError(t *testing.T) {
+	response, err := DeleteAccount(&models.Account{Data: &models.AccountData{ID: "some-id"}})
+	assert.NotNil(t, err)
+	assert.Nil(t, response)
+}
@@ -0,0 +1,104 @@
+package accounts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors matched by APIError through errors.Is, based on the response status code.
+var (
+	ErrBadRequest  = sentinelError("bad request")
+	ErrNotFound    = sentinelError("not found")
+	ErrConflict    = sentinelError("conflict")
+	ErrRateLimited = sentinelError("rate limited")
+)
+
+type sentinelError string
+
+func (e sentinelError) Error() string {
+	return "accounts: " + string(e)
+}
+
+// RequestIDHeader is the response header holding the ID the API assigned to the request.
+const RequestIDHeader = "X-Request-Id"
+
+// APIError is returned by every operation when the API answers with an unexpected status code.
+// Use errors.Is with ErrBadRequest, ErrNotFound, ErrConflict or ErrRateLimited to check for common cases,
+// or errors.As to access the details.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// ErrorMessage and ErrorCode are decoded from the Form3 error body, when present.
+	ErrorMessage string
+	ErrorCode    string
+	// RequestID is the value of the RequestIDHeader of the response, when present.
+	RequestID string
+	// Method and URL identify the request that failed.
+	Method string
+	URL    string
+	// Body is the raw response body.
+	Body []byte
+	// Attempts is the number of times the request was sent, according to the retry policy.
+	Attempts int
+}
+
+// errorBody is the body the Form3 API returns alongside error status codes.
+type errorBody struct {
+	ErrorMessage string `json:"error_message"`
+	ErrorCode    string `json:"error_code"`
+}
+
+// newAPIError builds an APIError from a response and its already read body.
+func newAPIError(response *http.Response, body []byte) *APIError {
+	apiError := &APIError{
+		StatusCode: response.StatusCode,
+		RequestID:  response.Header.Get(RequestIDHeader),
+		Body:       body,
+		Attempts:   1,
+	}
+	if response.Request != nil {
+		apiError.Method = response.Request.Method
+		apiError.URL = response.Request.URL.String()
+		apiError.Attempts = attemptFromContext(response.Request.Context())
+	}
+	// The body is not always JSON (e.g. proxies), in which case only the raw body is kept.
+	var decodedBody errorBody
+	if json.Unmarshal(body, &decodedBody) == nil {
+		apiError.ErrorMessage = decodedBody.ErrorMessage
+		apiError.ErrorCode = decodedBody.ErrorCode
+	}
+	return apiError
+}
+
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("accounts: %s %s returned status code %d", e.Method, e.URL, e.StatusCode)
+	if e.ErrorMessage != "" {
+		message += ": " + e.ErrorMessage
+	}
+	if e.ErrorCode != "" {
+		message += " (error code " + e.ErrorCode + ")"
+	}
+	if e.RequestID != "" {
+		message += " [request ID " + e.RequestID + "]"
+	}
+	if e.Attempts > 1 {
+		message += fmt.Sprintf(" after %d attempts", e.Attempts)
+	}
+	return message
+}
+
+// Is reports whether the error matches one of the sentinel errors of this package.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
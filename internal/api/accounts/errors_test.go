@@ -0,0 +1,69 @@
+package accounts
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIError_DecodesForm3ErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIDHeader, "request-1")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error_message":"record some-id does not exist","error_code":"not_found"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		_, err := client.Fetch("some-id")
+		var apiError *APIError
+		if assert.True(t, errors.As(err, &apiError)) {
+			assert.Equal(t, http.StatusNotFound, apiError.StatusCode)
+			assert.Equal(t, "record some-id does not exist", apiError.ErrorMessage)
+			assert.Equal(t, "not_found", apiError.ErrorCode)
+			assert.Equal(t, "request-1", apiError.RequestID)
+			assert.Equal(t, http.MethodGet, apiError.Method)
+			assert.Equal(t, server.URL+"/v1/organisation/accounts/some-id", apiError.URL)
+		}
+	}
+}
+
+func TestAPIError_KeepsBodyThatIsNotJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("bad gateway"))
+	}))
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		_, err := client.Delete("some-id", 0)
+		var apiError *APIError
+		if assert.True(t, errors.As(err, &apiError)) {
+			assert.Equal(t, http.StatusBadGateway, apiError.StatusCode)
+			assert.Equal(t, "", apiError.ErrorMessage)
+			assert.Equal(t, []byte("bad gateway"), apiError.Body)
+		}
+	}
+}
+
+func TestAPIError_MatchesSentinelErrors(t *testing.T) {
+	cases := map[int]error{
+		http.StatusBadRequest:      ErrBadRequest,
+		http.StatusNotFound:        ErrNotFound,
+		http.StatusConflict:        ErrConflict,
+		http.StatusTooManyRequests: ErrRateLimited,
+	}
+	for statusCode, sentinel := range cases {
+		err := &APIError{StatusCode: statusCode}
+		assert.ErrorIs(t, err, sentinel)
+		for _, other := range cases {
+			if other != sentinel {
+				assert.NotErrorIs(t, err, other)
+			}
+		}
+	}
+}
@@ -1,44 +1,68 @@
 package accounts
 
 import (
-	"errors"
-	"fmt"
-	"github.com/nambroa/interview-accountapi/internal"
+	"context"
 	"github.com/nambroa/interview-accountapi/internal/models"
 	"github.com/nambroa/interview-accountapi/internal/models/builder"
-	"io"
 	"log"
 	"net/http"
 )
 
-// Fetch fetches an account from the fake API based on its ID.
-func Fetch(accountID string) (*models.Account, error) {
-	var fetchAccountURL = internal.AccountURL + "/" + accountID
+// Fetch fetches an account from the fake API based on its ID, using the DefaultClient.
+func Fetch(accountID string, options ...RequestOption) (*models.Account, error) {
+	return DefaultClient.Fetch(accountID, options...)
+}
+
+// FetchContext is like Fetch but uses ctx for the request.
+func FetchContext(ctx context.Context, accountID string, options ...RequestOption) (*models.Account, error) {
+	return DefaultClient.FetchContext(ctx, accountID, options...)
+}
+
+// Fetch fetches an account from the API based on its ID. If the API does not answer with 200 OK, the error is an *APIError.
+func (c *AccountsClient) Fetch(accountID string, options ...RequestOption) (*models.Account, error) {
+	return c.FetchContext(context.Background(), accountID, options...)
+}
+
+// FetchContext is like Fetch but uses ctx for the request. If ctx is cancelled or its deadline is exceeded
+// before the response is read, ctx.Err() is returned.
+func (c *AccountsClient) FetchContext(ctx context.Context, accountID string, options ...RequestOption) (*models.Account, error) {
+	requestOptions := newRequestOptions(options)
+	var fetchAccountURL = c.accountURL(accountID)
 
+	// Build request
+	request, err := c.newRequest(ctx, OperationFetch, http.MethodGet, fetchAccountURL, nil)
+	if err != nil {
+		log.Println("Error found while building fetch account request:", err)
+		return nil, err
+	}
 	// Fetch account
-	response, err := http.Get(fetchAccountURL)
+	response, accountJSON, err := c.do(request, requestOptions)
 
 	// Process response
 	if err != nil {
 		log.Println("Error found while fetching account:", err)
 		return nil, err
 	}
-	defer response.Body.Close()
-	accountJSON, _ := io.ReadAll(response.Body)
 	if response.StatusCode != http.StatusOK {
 		log.Println("Response returned error status code:", response.StatusCode)
-		return nil, errors.New(fmt.Sprintf("Status code: %d. Body: %s", response.StatusCode, string(accountJSON)))
+		return nil, newAPIError(response, accountJSON)
 	}
 
+	return c.decodeAccount(accountJSON)
+}
+
+// decodeAccount unmarshals an account returned by the API according to the decode options of the client and validates
+// its fields, see builder.AccountBuilder.BuildReceived.
+func (c *AccountsClient) decodeAccount(accountJSON []byte) (*models.Account, error) {
 	// Unmarshal payload into account.
-	accountBuilder, err := builder.FromJSON(accountJSON)
+	accountBuilder, err := builder.FromJSONWithOptions(accountJSON, c.decodeOptions)
 	if err != nil {
 		log.Println("Error found while unmarshalling account:", err)
 		return nil, err
 	}
 
-	// Build account.
-	account, err := accountBuilder.Build()
+	// Build account, without the rules that only apply to the accounts sent to the API.
+	account, err := accountBuilder.BuildReceived()
 	if err != nil {
 		log.Println("Error found while building account:", err)
 		return nil, err
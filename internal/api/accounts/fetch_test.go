@@ -1,11 +1,16 @@
 package accounts
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/nambroa/interview-accountapi/internal"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"github.com/nambroa/interview-accountapi/internal/models/builder"
 	uuid "github.com/nu7hatch/gouuid"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"net/http"
-	"strconv"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -29,5 +34,100 @@ func TestFetch_WithNonExistentIDReturnsNotFound(t *testing.T) {
 	fetchedAcc, err := Fetch(fakeID.String())
 	assert.NotNil(t, err)
 	assert.Nil(t, fetchedAcc)
-	assert.ErrorContains(t, err, strconv.Itoa(http.StatusNotFound))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFetch_LosslessKeepsUnknownFieldsWhenCreatingAgain(t *testing.T) {
+	acc, err := newTestAccount()
+	if !assert.Nil(t, err) {
+		return
+	}
+	accountJSON, err := json.Marshal(acc)
+	if !assert.Nil(t, err) {
+		return
+	}
+	// Add fields unknown to the models, as a newer version of the API would return them.
+	var fields map[string]map[string]json.RawMessage
+	json.Unmarshal(accountJSON, &fields)
+	fields["data"]["relationships"] = json.RawMessage(`{"master_account":{"data":[{"type":"accounts","id":"a52d13a4-f435-4c00-cfad-f5e7ac5972df"}]}}`)
+	var attributes map[string]json.RawMessage
+	json.Unmarshal(fields["data"]["attributes"], &attributes)
+	attributes["private_identification"] = json.RawMessage(`{"birth_date":"2017-07-23","birth_country":"GB"}`)
+	fields["data"]["attributes"], _ = json.Marshal(attributes)
+	sourceJSON, _ := json.Marshal(fields)
+
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(sourceJSON)
+	}))
+	defer source.Close()
+	var idempotencyKeys []string
+	destination := newAccountStoreServer(0, &idempotencyKeys)
+	defer destination.Close()
+	sourceClient, err := NewAccountsClient(Config{BaseURL: source.URL, DecodeOptions: builder.DecodeOptions{Lossless: true}})
+	assert.Nil(t, err)
+	destinationClient, err := NewAccountsClient(Config{BaseURL: destination.URL})
+	assert.Nil(t, err)
+
+	// Copy account between environments
+	fetchedAcc, err := sourceClient.Fetch(acc.Data.ID)
+	if assert.Nil(t, err) {
+		assert.JSONEq(t, `{"birth_date":"2017-07-23","birth_country":"GB"}`, string(fetchedAcc.Data.Attributes.Extra["private_identification"]))
+		_, err = destinationClient.Create(fetchedAcc)
+		if assert.Nil(t, err) {
+			response, err := http.Get(destinationClient.accountURL(acc.Data.ID))
+			if assert.Nil(t, err) {
+				defer response.Body.Close()
+				copiedJSON, _ := io.ReadAll(response.Body)
+				assert.JSONEq(t, string(sourceJSON), string(copiedJSON))
+			}
+		}
+	}
+}
+
+func TestFetch_WithoutLosslessDropsUnknownFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"attributes":{"bank_id":"400300","bank_id_code":"GBDSC","base_currency":"GBP","bic":"NWBKGB22","country":"GB","name":["Batman"],"private_identification":{"birth_country":"GB"}},"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","organisation_id":"eb0bd6f5-c3f5-44b2-b677-acd23cdde73c","type":"accounts","version":0}}`)
+	}))
+	defer server.Close()
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	assert.Nil(t, err)
+
+	fetchedAcc, err := client.Fetch("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	if assert.Nil(t, err) {
+		assert.Nil(t, fetchedAcc.Data.Attributes.Extra)
+		marshalledAcc, _ := json.Marshal(fetchedAcc)
+		assert.NotContains(t, string(marshalledAcc), "private_identification")
+	}
+}
+
+func TestFetch_StrictReturnsDecodeErrorOnAPIDrift(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"attributes":{"bank_id":"400300","country":"GB","name":["Batman"],"status_reason":"unspecified"},"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","type":"accounts","version":"0"}}`)
+	}))
+	defer server.Close()
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, DecodeOptions: builder.DecodeOptions{Strict: true}})
+	assert.Nil(t, err)
+
+	fetchedAcc, err := client.Fetch("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	assert.Nil(t, fetchedAcc)
+	var decodeError *builder.DecodeError
+	if assert.ErrorAs(t, err, &decodeError) {
+		assert.Equal(t, []builder.FieldError{
+			{Path: "data.attributes.status_reason", Problem: "unknown field"},
+			{Path: "data.version", Problem: "expected integer but got string"},
+		}, decodeError.Fields)
+	}
+}
+
+func TestFetch_WithUnknownStatusReturnsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"attributes":{"bank_id":"400300","country":"GB","name":["Batman"],"status":"faied"},"id":"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc","type":"accounts","version":0}}`)
+	}))
+	defer server.Close()
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	assert.Nil(t, err)
+
+	fetchedAcc, err := client.Fetch("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+	assert.Nil(t, fetchedAcc)
+	assert.ErrorIs(t, err, models.ErrUnknownValue)
 }
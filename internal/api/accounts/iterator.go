@@ -0,0 +1,148 @@
+package accounts
+
+import (
+	"context"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"iter"
+)
+
+// AccountIterator walks over every account matching a List call, following the next link of each page.
+// Pages are fetched lazily, so only the current page (and the next one, when prefetching) is kept in memory.
+// An AccountIterator is not safe for concurrent use.
+//
+//	it := client.Iterate(ctx, &accounts.ListOptions{PageSize: 100})
+//	for it.Next() {
+//		account := it.Account()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type AccountIterator struct {
+	client   *AccountsClient
+	ctx      context.Context
+	options  *ListOptions
+	prefetch bool
+
+	started bool
+	page    *AccountPage
+	index   int
+	account *models.Account
+	pending chan pageResult
+	err     error
+}
+
+// pageResult is the outcome of a page fetched in the background.
+type pageResult struct {
+	page *AccountPage
+	err  error
+}
+
+// Iterate returns an iterator over the accounts of the fake API matching the options, using the DefaultClient.
+func Iterate(ctx context.Context, options *ListOptions) *AccountIterator {
+	return DefaultClient.Iterate(ctx, options)
+}
+
+// Iterate returns an iterator over the accounts matching the options. Options may be nil.
+// No request is sent until the first call to Next.
+func (c *AccountsClient) Iterate(ctx context.Context, options *ListOptions) *AccountIterator {
+	return &AccountIterator{client: c, ctx: ctx, options: options}
+}
+
+// WithPrefetch enables fetching the next page in a background goroutine while the current page is consumed.
+// It must be called before the first call to Next.
+func (it *AccountIterator) WithPrefetch(prefetch bool) *AccountIterator {
+	it.prefetch = prefetch
+	return it
+}
+
+// Next advances the iterator to the next account, fetching the next page if needed.
+// It returns false when there are no more accounts or an error occurred, which is then returned by Err.
+func (it *AccountIterator) Next() bool {
+	it.account = nil
+	if it.err != nil {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+	for it.page == nil || it.index >= len(it.page.Accounts) {
+		page, more, err := it.nextPage()
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if !more {
+			return false
+		}
+		it.page = page
+		it.index = 0
+		if it.prefetch && page.HasNext() {
+			it.startPrefetch(page.Links.Next)
+		}
+	}
+	it.account = &it.page.Accounts[it.index]
+	it.index++
+	return true
+}
+
+// Account returns the current account. It is only valid after a call to Next returned true.
+func (it *AccountIterator) Account() *models.Account {
+	return it.account
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *AccountIterator) Err() error {
+	return it.err
+}
+
+// All returns the remaining accounts as a sequence for use in range loops. If the iteration fails,
+// the error is yielded last with a nil account.
+func (it *AccountIterator) All() iter.Seq2[*models.Account, error] {
+	return func(yield func(*models.Account, error) bool) {
+		for it.Next() {
+			if !yield(it.Account(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// nextPage returns the page after the current one. more is false when the last page was already returned.
+func (it *AccountIterator) nextPage() (page *AccountPage, more bool, err error) {
+	if !it.started {
+		it.started = true
+		page, err = it.client.ListContext(it.ctx, it.options)
+		return page, true, err
+	}
+	if it.pending != nil {
+		pending := it.pending
+		it.pending = nil
+		select {
+		case result := <-pending:
+			return result.page, true, result.err
+		case <-it.ctx.Done():
+			return nil, true, it.ctx.Err()
+		}
+	}
+	if !it.page.HasNext() {
+		return nil, false, nil
+	}
+	page, err = it.client.ListPageContext(it.ctx, it.page.Links.Next)
+	return page, true, err
+}
+
+// startPrefetch fetches the page behind link in the background. The channel is buffered so the goroutine
+// never blocks, even if the iterator is abandoned.
+func (it *AccountIterator) startPrefetch(link string) {
+	pending := make(chan pageResult, 1)
+	go func() {
+		page, err := it.client.ListPageContext(it.ctx, link)
+		pending <- pageResult{page: page, err: err}
+	}()
+	it.pending = pending
+}
@@ -0,0 +1,131 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// newPagedServer serves pages accounts, with accountsPerPage accounts each. Requests for failingPage fail.
+func newPagedServer(pages, accountsPerPage, failingPage int, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		pageNumber, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+		if pageNumber == failingPage {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"data":[`)
+		for i := 0; i < accountsPerPage; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"id":"%d-%d","type":"accounts"}`, pageNumber, i)
+		}
+		fmt.Fprint(w, `],"links":{`)
+		if pageNumber < pages-1 {
+			fmt.Fprintf(w, `"next":"/v1/organisation/accounts?page%%5Bnumber%%5D=%d"`, pageNumber+1)
+		}
+		fmt.Fprint(w, `}}`)
+	}))
+}
+
+func TestAccountIterator_WalksEveryPage(t *testing.T) {
+	for _, prefetch := range []bool{false, true} {
+		var requests int32
+		server := newPagedServer(3, 2, -1, &requests)
+		client, err := NewAccountsClient(Config{BaseURL: server.URL})
+		if assert.Nil(t, err) {
+			it := client.Iterate(context.Background(), nil).WithPrefetch(prefetch)
+			var IDs []string
+			for it.Next() {
+				IDs = append(IDs, it.Account().Data.ID)
+			}
+			assert.Nil(t, it.Err())
+			assert.Equal(t, []string{"0-0", "0-1", "1-0", "1-1", "2-0", "2-1"}, IDs)
+			assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+			assert.False(t, it.Next())
+		}
+		server.Close()
+	}
+}
+
+func TestAccountIterator_StopsAtFailingPage(t *testing.T) {
+	var requests int32
+	server := newPagedServer(3, 2, 1, &requests)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		it := client.Iterate(context.Background(), nil).WithPrefetch(true)
+		count := 0
+		for it.Next() {
+			count++
+		}
+		assert.Equal(t, 2, count)
+		var apiError *APIError
+		if assert.ErrorAs(t, it.Err(), &apiError) {
+			assert.Equal(t, http.StatusInternalServerError, apiError.StatusCode)
+		}
+	}
+}
+
+func TestAccountIterator_StopsWhenContextIsCancelled(t *testing.T) {
+	var requests int32
+	server := newPagedServer(3, 2, -1, &requests)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		ctx, cancel := context.WithCancel(context.Background())
+		it := client.Iterate(ctx, nil)
+		assert.True(t, it.Next())
+		cancel()
+		assert.False(t, it.Next())
+		assert.Nil(t, it.Account())
+		assert.Equal(t, context.Canceled, it.Err())
+	}
+}
+
+func TestAccountIterator_AllYieldsAccountsAndError(t *testing.T) {
+	var requests int32
+	server := newPagedServer(3, 2, 2, &requests)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		var accounts []*models.Account
+		var iterationErr error
+		for account, err := range client.Iterate(context.Background(), nil).All() {
+			if err != nil {
+				iterationErr = err
+				break
+			}
+			accounts = append(accounts, account)
+		}
+		assert.Len(t, accounts, 4)
+		assert.NotNil(t, iterationErr)
+	}
+}
+
+func TestAccountIterator_AllStopsFetchingWhenLoopBreaks(t *testing.T) {
+	var requests int32
+	server := newPagedServer(3, 2, -1, &requests)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		for account, err := range client.Iterate(context.Background(), nil).All() {
+			assert.Nil(t, err)
+			assert.Equal(t, "0-0", account.Data.ID)
+			break
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	}
+}
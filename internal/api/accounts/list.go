@@ -0,0 +1,146 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"github.com/nambroa/interview-accountapi/internal/models/builder"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListFilter restricts the accounts returned by List. Empty fields are not sent to the API.
+type ListFilter struct {
+	BankID        string
+	BankIDCode    string
+	AccountNumber string
+	Iban          string
+	CustomerID    string
+	Country       string
+}
+
+// ListOptions contains the paging and filtering parameters of List. Page numbers start at 0.
+// A zero PageSize lets the API pick its default page size.
+type ListOptions struct {
+	PageNumber int
+	PageSize   int
+	Filter     ListFilter
+}
+
+// AccountPage is a page of accounts returned by List, alongside the links to the other pages.
+type AccountPage struct {
+	Accounts []models.Account
+	Links    models.Links
+	Meta     map[string]json.RawMessage
+}
+
+// HasNext reports whether there is a page after this one.
+func (p *AccountPage) HasNext() bool {
+	return p.Links.Next != ""
+}
+
+// query encodes the options as Form3 query parameters (page[number], page[size] and filter[...]).
+func (o *ListOptions) query() url.Values {
+	query := url.Values{}
+	if o == nil {
+		return query
+	}
+	if o.PageNumber > 0 {
+		query.Set("page[number]", strconv.Itoa(o.PageNumber))
+	}
+	if o.PageSize > 0 {
+		query.Set("page[size]", strconv.Itoa(o.PageSize))
+	}
+	filters := map[string]string{
+		"bank_id":        o.Filter.BankID,
+		"bank_id_code":   o.Filter.BankIDCode,
+		"account_number": o.Filter.AccountNumber,
+		"iban":           o.Filter.Iban,
+		"customer_id":    o.Filter.CustomerID,
+		"country":        o.Filter.Country,
+	}
+	for name, value := range filters {
+		if value != "" {
+			query.Set("filter["+name+"]", value)
+		}
+	}
+	return query
+}
+
+// List lists the accounts of the fake API matching the list options, using the DefaultClient. List options may be nil.
+func List(listOptions *ListOptions, options ...RequestOption) (*AccountPage, error) {
+	return DefaultClient.List(listOptions, options...)
+}
+
+// ListContext is like List but uses ctx for the request.
+func ListContext(ctx context.Context, listOptions *ListOptions, options ...RequestOption) (*AccountPage, error) {
+	return DefaultClient.ListContext(ctx, listOptions, options...)
+}
+
+// List lists the accounts matching the list options, one page at a time. List options may be nil.
+// Accounts are returned as sent by the API, without validation.
+// If the API does not answer with 200 OK, the error is an *APIError.
+func (c *AccountsClient) List(listOptions *ListOptions, options ...RequestOption) (*AccountPage, error) {
+	return c.ListContext(context.Background(), listOptions, options...)
+}
+
+// ListContext is like List but uses ctx for the request. If ctx is cancelled or its deadline is exceeded
+// before the response is read, ctx.Err() is returned.
+func (c *AccountsClient) ListContext(ctx context.Context, listOptions *ListOptions, options ...RequestOption) (*AccountPage, error) {
+	var listAccountsURL = c.accountsURL
+	if query := listOptions.query(); len(query) > 0 {
+		listAccountsURL += "?" + query.Encode()
+	}
+	return c.listPage(ctx, listAccountsURL, newRequestOptions(options))
+}
+
+// ListPageContext fetches the page a link of an AccountPage points to, for example page.Links.Next.
+// Relative links are resolved against the base URL of the client.
+func (c *AccountsClient) ListPageContext(ctx context.Context, link string, options ...RequestOption) (*AccountPage, error) {
+	pageURL, err := c.baseURL.Parse(link)
+	if err != nil {
+		log.Println("Error found while parsing page link:", err)
+		return nil, err
+	}
+	return c.listPage(ctx, pageURL.String(), newRequestOptions(options))
+}
+
+func (c *AccountsClient) listPage(ctx context.Context, pageURL string, requestOptions *requestOptions) (*AccountPage, error) {
+	// Build request
+	request, err := c.newRequest(ctx, OperationList, http.MethodGet, pageURL, nil)
+	if err != nil {
+		log.Println("Error found while building list accounts request:", err)
+		return nil, err
+	}
+	// List accounts
+	response, body, err := c.do(request, requestOptions)
+
+	// Process response
+	if err != nil {
+		log.Println("Error found while listing accounts:", err)
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		log.Println("Response returned error status code:", response.StatusCode)
+		return nil, newAPIError(response, body)
+	}
+
+	// Unmarshal payload into accounts.
+	accountList, err := builder.AccountListFromJSON(body, c.decodeOptions)
+	if err != nil {
+		log.Println("Error found while unmarshalling accounts:", err)
+		return nil, err
+	}
+
+	page := &AccountPage{Accounts: make([]models.Account, 0, len(accountList.Data))}
+	for _, accountData := range accountList.Data {
+		page.Accounts = append(page.Accounts, models.Account{Data: accountData})
+	}
+	if accountList.Links != nil {
+		page.Links = *accountList.Links
+	}
+	page.Meta = accountList.Meta
+	return page, nil
+}
@@ -0,0 +1,98 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"github.com/nambroa/interview-accountapi/internal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestList_WithPageSizeOneReturnsOneAccount(t *testing.T) {
+	acc, err := internal.DefaultAccountBuilder().Build()
+	if assert.Nil(t, err) {
+		// Create account so there is at least one to list
+		_, err := Create(acc)
+		if assert.Nil(t, err) {
+			// List accounts
+			page, err := List(&ListOptions{PageSize: 1})
+			assert.Nil(t, err)
+			if assert.NotNil(t, page) {
+				assert.Len(t, page.Accounts, 1)
+				assert.NotEmpty(t, page.Links.First)
+			}
+		}
+	}
+}
+
+func TestListOptions_EncodesPagingAndFilters(t *testing.T) {
+	options := &ListOptions{
+		PageNumber: 2,
+		PageSize:   50,
+		Filter:     ListFilter{BankID: "400300", Country: "GB", Iban: ""},
+	}
+	query := options.query()
+	assert.Equal(t, "2", query.Get("page[number]"))
+	assert.Equal(t, "50", query.Get("page[size]"))
+	assert.Equal(t, "400300", query.Get("filter[bank_id]"))
+	assert.Equal(t, "GB", query.Get("filter[country]"))
+	assert.NotContains(t, query, "filter[iban]")
+}
+
+func TestListOptions_NilOptionsSendNoParameters(t *testing.T) {
+	var options *ListOptions
+	assert.Empty(t, options.query())
+}
+
+func TestList_DecodesAccountsAndFollowsNextLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page[number]")
+		switch page {
+		case "":
+			fmt.Fprint(w, `{"data":[{"id":"first","type":"accounts"},{"id":"second","type":"accounts"}],
+				"links":{"first":"/v1/organisation/accounts?page%5Bnumber%5D=first","next":"/v1/organisation/accounts?page%5Bnumber%5D=1"}}`)
+		case "1":
+			fmt.Fprint(w, `{"data":[{"id":"third","type":"accounts"}],"links":{"prev":"/v1/organisation/accounts"},"meta":{"count":3}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		page, err := client.List(nil)
+		if assert.Nil(t, err) {
+			if assert.Len(t, page.Accounts, 2) {
+				assert.Equal(t, "first", page.Accounts[0].Data.ID)
+				assert.Equal(t, "second", page.Accounts[1].Data.ID)
+			}
+			assert.True(t, page.HasNext())
+
+			page, err = client.ListPageContext(context.Background(), page.Links.Next)
+			if assert.Nil(t, err) {
+				if assert.Len(t, page.Accounts, 1) {
+					assert.Equal(t, "third", page.Accounts[0].Data.ID)
+				}
+				assert.False(t, page.HasNext())
+				assert.JSONEq(t, "3", string(page.Meta["count"]))
+			}
+		}
+	}
+}
+
+func TestList_WithErrorStatusReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL})
+	if assert.Nil(t, err) {
+		page, err := client.List(&ListOptions{PageSize: -1})
+		assert.Nil(t, page)
+		assert.ErrorIs(t, err, ErrBadRequest)
+	}
+}
@@ -0,0 +1,53 @@
+package accounts
+
+import (
+	"context"
+	"net/http"
+)
+
+// Doer sends a single HTTP request and returns its response. *http.Client implements it.
+type Doer interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function to the Doer interface.
+type DoerFunc func(request *http.Request) (*http.Response, error)
+
+// Do calls f(request).
+func (f DoerFunc) Do(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// Middleware wraps the Doer used to send requests, to add behaviour around every attempt of every request
+// (for example a circuit breaker). Middlewares run inside the retry loop of the client.
+type Middleware func(next Doer) Doer
+
+// chain wraps doer with the middlewares, the first middleware being the outermost one.
+func chain(doer Doer, middlewares []Middleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		doer = middlewares[i](doer)
+	}
+	return doer
+}
+
+// Operation identifies the operation of the client a request belongs to, so middlewares can treat operations
+// differently (for example with per-operation rate limits).
+type Operation string
+
+const (
+	OperationCreate Operation = "create"
+	OperationFetch  Operation = "fetch"
+	OperationList   Operation = "list"
+	OperationUpdate Operation = "update"
+	OperationDelete Operation = "delete"
+)
+
+// operationContextKey is the context key holding the Operation of a request.
+type operationContextKey struct{}
+
+// OperationFromContext returns the operation a request belongs to, given the request context.
+// It returns an empty Operation for requests that were not sent by an AccountsClient.
+func OperationFromContext(ctx context.Context) Operation {
+	operation, _ := ctx.Value(operationContextKey{}).(Operation)
+	return operation
+}
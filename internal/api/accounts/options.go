@@ -0,0 +1,50 @@
+package accounts
+
+// RequestOption customises a single call to an operation of the client.
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the settings of a single call, built from its RequestOption list.
+type requestOptions struct {
+	ignoreNotFound bool
+	idempotent     bool
+	retryPolicy    RetryPolicy
+	idempotencyKey string
+}
+
+func newRequestOptions(options []RequestOption) *requestOptions {
+	requestOptions := &requestOptions{}
+	for _, option := range options {
+		option(requestOptions)
+	}
+	return requestOptions
+}
+
+// IgnoreNotFound makes Delete treat a 404 Not Found response as a success, so deleting an account that is already
+// gone is not an error. Useful for cleanup jobs.
+func IgnoreNotFound() RequestOption {
+	return func(options *requestOptions) {
+		options.ignoreNotFound = true
+	}
+}
+
+// Idempotent marks the call as safe to send more than once, allowing the retry policy to retry a Create or an Update.
+func Idempotent() RequestOption {
+	return func(options *requestOptions) {
+		options.idempotent = true
+	}
+}
+
+// WithRetryPolicy overrides the RetryPolicy of the client for the call. Use NoRetry to disable retries.
+func WithRetryPolicy(policy RetryPolicy) RequestOption {
+	return func(options *requestOptions) {
+		options.retryPolicy = policy
+	}
+}
+
+// WithIdempotencyKey sets the idempotency key sent with a Create, instead of the one generated for every call.
+// Reuse the same key when creating the same account again, for example when retrying a Create that timed out.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(options *requestOptions) {
+		options.idempotencyKey = key
+	}
+}
@@ -0,0 +1,177 @@
+package accounts
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitRemainingHeader is the response header holding the number of requests the API still accepts
+// in its current window.
+const RateLimitRemainingHeader = "X-RateLimit-Remaining"
+
+// RateLimit is the rate of a token bucket: Rate requests per second on average, with bursts of up to Burst requests.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimiterConfig contains the limits of a RateLimiter.
+type RateLimiterConfig struct {
+	// Limit applies to every request, whatever its operation. A zero Rate disables it.
+	Limit RateLimit
+	// Operations contains the limits of specific operations, each with its own bucket. Requests of these
+	// operations must get a token from both their operation bucket and the Limit bucket.
+	Operations map[Operation]RateLimit
+	// MinRateRatio is the lowest fraction of the configured rate the limiter slows down to when the API
+	// pushes back. Defaults to 0.1.
+	MinRateRatio float64
+}
+
+// RateLimiter is a client-side token bucket limiter. Requests wait for a token, or until their context is done.
+// When the API answers with 429 Too Many Requests or reports no remaining requests in RateLimitRemainingHeader,
+// the rate is halved (down to MinRateRatio of the configured rate) and then recovers gradually with successful responses.
+// Clients sharing a RateLimiter take their tokens from the same buckets, so they stay under the limit together.
+type RateLimiter struct {
+	limit      *tokenBucket
+	operations map[Operation]*tokenBucket
+}
+
+// NewRateLimiter builds a RateLimiter from the given configuration.
+func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
+	minRateRatio := config.MinRateRatio
+	if minRateRatio <= 0 || minRateRatio > 1 {
+		minRateRatio = 0.1
+	}
+	limiter := &RateLimiter{operations: map[Operation]*tokenBucket{}}
+	if config.Limit.Rate > 0 {
+		limiter.limit = newTokenBucket(config.Limit, minRateRatio)
+	}
+	for operation, limit := range config.Operations {
+		if limit.Rate > 0 {
+			limiter.operations[operation] = newTokenBucket(limit, minRateRatio)
+		}
+	}
+	return limiter
+}
+
+// Middleware returns the Middleware that makes requests wait for the rate limiter.
+func (l *RateLimiter) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			buckets := l.buckets(OperationFromContext(request.Context()))
+			for _, bucket := range buckets {
+				if err := bucket.wait(request.Context()); err != nil {
+					return nil, err
+				}
+			}
+			response, err := next.Do(request)
+			if err == nil {
+				for _, bucket := range buckets {
+					bucket.observe(response)
+				}
+			}
+			return response, err
+		})
+	}
+}
+
+// buckets returns the buckets a request of the given operation must get a token from.
+func (l *RateLimiter) buckets(operation Operation) []*tokenBucket {
+	var buckets []*tokenBucket
+	if bucket, ok := l.operations[operation]; ok {
+		buckets = append(buckets, bucket)
+	}
+	if l.limit != nil {
+		buckets = append(buckets, l.limit)
+	}
+	return buckets
+}
+
+// tokenBucket refills at rate tokens per second, up to burst tokens. Its rate adapts between minRate and maxRate.
+type tokenBucket struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	rate    float64
+	minRate float64
+	maxRate float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+}
+
+func newTokenBucket(limit RateLimit, minRateRatio float64) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		now:     time.Now,
+		rate:    limit.Rate,
+		minRate: limit.Rate * minRateRatio,
+		maxRate: limit.Rate,
+		burst:   burst,
+		tokens:  burst,
+		last:    time.Now(),
+	}
+}
+
+// wait takes a token, blocking until one is available or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		b.refill()
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		if err := wait(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// refill adds the tokens earned since the last refill. b.mu must be held.
+func (b *tokenBucket) refill() {
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+// observe adapts the bucket to the feedback of the API carried by a response.
+func (b *tokenBucket) observe(response *http.Response) {
+	remaining, err := strconv.Atoi(response.Header.Get(RateLimitRemainingHeader))
+	hasRemaining := err == nil
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests || (hasRemaining && remaining <= 0):
+		b.tokens = 0
+		b.rate /= 2
+		if b.rate < b.minRate {
+			b.rate = b.minRate
+		}
+		log.Println("Rate limited by the API, slowing down to", b.rate, "requests per second")
+	case hasRemaining && float64(remaining) < b.tokens:
+		b.tokens = float64(remaining)
+	case response.StatusCode < http.StatusBadRequest && b.rate < b.maxRate:
+		// Recover a tenth of the configured rate with every successful response.
+		b.rate += b.maxRate / 10
+		if b.rate > b.maxRate {
+			b.rate = b.maxRate
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package accounts
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestBucket returns a token bucket with a clock controlled by the returned function.
+func newTestBucket(limit RateLimit) (*tokenBucket, func(time.Duration)) {
+	bucket := newTokenBucket(limit, 0.1)
+	now := time.Now()
+	bucket.now = func() time.Time { return now }
+	bucket.last = now
+	return bucket, func(elapsed time.Duration) { now = now.Add(elapsed) }
+}
+
+func remainingHeader(remaining string) http.Header {
+	header := http.Header{}
+	header.Set(RateLimitRemainingHeader, remaining)
+	return header
+}
+
+func TestTokenBucket_RefillsAtRateUpToBurst(t *testing.T) {
+	bucket, advance := newTestBucket(RateLimit{Rate: 10, Burst: 2})
+	assert.Nil(t, bucket.wait(context.Background()))
+	assert.Nil(t, bucket.wait(context.Background()))
+	assert.InDelta(t, 0, bucket.tokens, 0.001)
+
+	advance(time.Second)
+	bucket.refill()
+	assert.InDelta(t, 2, bucket.tokens, 0.001)
+}
+
+func TestTokenBucket_WaitRespectsContext(t *testing.T) {
+	bucket, _ := newTestBucket(RateLimit{Rate: 0.001, Burst: 1})
+	assert.Nil(t, bucket.wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, bucket.wait(ctx))
+}
+
+func TestTokenBucket_SlowsDownOnTooManyRequestsAndRecovers(t *testing.T) {
+	bucket, _ := newTestBucket(RateLimit{Rate: 100, Burst: 5})
+	bucket.observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	assert.Equal(t, 50.0, bucket.rate)
+	assert.Equal(t, 0.0, bucket.tokens)
+
+	for i := 0; i < 10; i++ {
+		bucket.observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	}
+	assert.Equal(t, 10.0, bucket.rate)
+
+	for i := 0; i < 20; i++ {
+		bucket.observe(&http.Response{StatusCode: http.StatusOK, Header: http.Header{}})
+	}
+	assert.Equal(t, 100.0, bucket.rate)
+}
+
+func TestTokenBucket_FollowsRateLimitRemainingHeader(t *testing.T) {
+	bucket, _ := newTestBucket(RateLimit{Rate: 100, Burst: 5})
+	bucket.observe(&http.Response{StatusCode: http.StatusOK, Header: remainingHeader("2")})
+	assert.Equal(t, 2.0, bucket.tokens)
+	assert.Equal(t, 100.0, bucket.rate)
+
+	bucket.observe(&http.Response{StatusCode: http.StatusOK, Header: remainingHeader("0")})
+	assert.Equal(t, 0.0, bucket.tokens)
+	assert.Equal(t, 50.0, bucket.rate)
+}
+
+func TestRateLimiter_LimitsEachOperationSeparately(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(RateLimiterConfig{Operations: map[Operation]RateLimit{OperationDelete: {Rate: 0.001, Burst: 1}}})
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, Middlewares: []Middleware{limiter.Middleware()}})
+	if assert.Nil(t, err) {
+		// Fetches are not limited.
+		for i := 0; i < 3; i++ {
+			client.Fetch("some-id")
+		}
+		client.Delete("some-id", 0)
+
+		// The second delete has to wait far longer than its deadline.
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := client.DeleteContext(ctx, "some-id", 0)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+	}
+}
+
+func TestRateLimiter_ClientLimitAppliesToEveryOperation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(RateLimiterConfig{Limit: RateLimit{Rate: 50, Burst: 1}})
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, Middlewares: []Middleware{limiter.Middleware()}})
+	if assert.Nil(t, err) {
+		start := time.Now()
+		client.Fetch("some-id")
+		client.Delete("some-id", 0)
+		client.List(nil)
+		// The first request uses the initial token, the next two wait 20ms each.
+		assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+	}
+}
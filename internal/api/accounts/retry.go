@@ -0,0 +1,145 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides whether a failed attempt of a request is retried, and how long to wait before the next attempt.
+// Whatever the policy, POST and PATCH requests are only retried when marked with the Idempotent option, and requests
+// are never retried once their context is done.
+type RetryPolicy interface {
+	// Retry is called after every attempt with its outcome, where attempt starts at 1. Either response or err is nil.
+	// The body of response has already been read and closed.
+	Retry(attempt int, request *http.Request, response *http.Response, err error) (delay time.Duration, retry bool)
+}
+
+// ExponentialBackoff is a RetryPolicy that retries network errors, 429 Too Many Requests and 5xx responses,
+// waiting a random delay between zero and BaseDelay * 2^(attempt-1), capped at MaxDelay ("full jitter").
+// When the response carries a Retry-After header, its delay is used instead, unless it is longer than MaxDelay:
+// the request is then not retried and the response is returned, so callers are never blocked longer than MaxDelay.
+type ExponentialBackoff struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used by clients that do not configure one.
+func DefaultRetryPolicy() RetryPolicy {
+	return &ExponentialBackoff{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second}
+}
+
+// NoRetry is a RetryPolicy that never retries.
+var NoRetry RetryPolicy = noRetry{}
+
+type noRetry struct{}
+
+func (noRetry) Retry(int, *http.Request, *http.Response, error) (time.Duration, bool) {
+	return 0, false
+}
+
+// Retry implements RetryPolicy.
+func (b *ExponentialBackoff) Retry(attempt int, request *http.Request, response *http.Response, err error) (time.Duration, bool) {
+	if attempt >= b.MaxAttempts || !isRetryable(response, err) {
+		return 0, false
+	}
+	if delay, ok := retryAfter(response); ok {
+		return delay, delay <= b.MaxDelay
+	}
+	maxDelay := b.BaseDelay << (attempt - 1)
+	if maxDelay > b.MaxDelay || maxDelay <= 0 {
+		maxDelay = b.MaxDelay
+	}
+	if maxDelay <= 0 {
+		return 0, true
+	}
+	return time.Duration(rand.Int63n(int64(maxDelay) + 1)), true
+}
+
+// isRetryable reports whether the outcome of an attempt is worth retrying: network errors, 429 and 5xx responses.
+// Requests rejected by an open circuit breaker are not retried.
+func isRetryable(response *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !isCircuitOpen(err)
+	}
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+}
+
+// retryAfter parses the Retry-After header of a response, given either in seconds or as an HTTP date.
+func retryAfter(response *http.Response) (time.Duration, bool) {
+	if response == nil {
+		return 0, false
+	}
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// RetryError is returned when a request failed without a response after being attempted several times.
+// The error of the last attempt is available through errors.Is and errors.As.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("accounts: request failed after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// attemptContextKey is the context key holding the number of the attempt a request belongs to.
+type attemptContextKey struct{}
+
+// attemptFromContext returns the number of the attempt a request belongs to, starting at 1.
+func attemptFromContext(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptContextKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
+
+// newAttempt returns a copy of the request for the given attempt, with a fresh body.
+func newAttempt(request *http.Request, attempt int) (*http.Request, error) {
+	attemptRequest := request.WithContext(context.WithValue(request.Context(), attemptContextKey{}, attempt))
+	if attempt > 1 && request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attemptRequest.Body = body
+	}
+	return attemptRequest, nil
+}
+
+// wait blocks for delay or until ctx is done, in which case ctx.Err() is returned.
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,175 @@
+package accounts
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fastRetryPolicy = &ExponentialBackoff{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+// newFlakyServer answers failures times with statusCode before answering with successStatusCode.
+func newFlakyServer(failures int32, statusCode, successStatusCode int, requests *int32, bodies *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := atomic.AddInt32(requests, 1)
+		if bodies != nil {
+			body, _ := io.ReadAll(r.Body)
+			*bodies = append(*bodies, string(body))
+		}
+		if request <= failures {
+			w.WriteHeader(statusCode)
+			return
+		}
+		w.WriteHeader(successStatusCode)
+	}))
+}
+
+func TestRetry_RetriesServerErrorsUntilSuccess(t *testing.T) {
+	var requests int32
+	server := newFlakyServer(2, http.StatusServiceUnavailable, http.StatusNoContent, &requests, nil)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: fastRetryPolicy})
+	if assert.Nil(t, err) {
+		_, err := client.Delete("some-id", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	}
+}
+
+func TestRetry_ReportsAttemptsInAPIError(t *testing.T) {
+	var requests int32
+	server := newFlakyServer(5, http.StatusTooManyRequests, http.StatusNoContent, &requests, nil)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: fastRetryPolicy})
+	if assert.Nil(t, err) {
+		_, err := client.Delete("some-id", 0)
+		var apiError *APIError
+		if assert.True(t, errors.As(err, &apiError)) {
+			assert.Equal(t, 3, apiError.Attempts)
+		}
+		assert.ErrorIs(t, err, ErrRateLimited)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	}
+}
+
+func TestRetry_DoesNotRetryClientErrors(t *testing.T) {
+	var requests int32
+	server := newFlakyServer(5, http.StatusNotFound, http.StatusNoContent, &requests, nil)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: fastRetryPolicy})
+	if assert.Nil(t, err) {
+		_, err := client.Delete("some-id", 0)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	}
+}
+
+func TestRetry_DoesNotRetryCreateUnlessIdempotent(t *testing.T) {
+	account, err := newTestAccount()
+	if assert.Nil(t, err) {
+		var requests int32
+		var bodies []string
+		server := newFlakyServer(1, http.StatusInternalServerError, http.StatusCreated, &requests, &bodies)
+		defer server.Close()
+
+		client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: fastRetryPolicy})
+		if assert.Nil(t, err) {
+			_, err := client.Create(account)
+			assert.NotNil(t, err)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+			// Marked as idempotent, the same payload is sent again.
+			atomic.StoreInt32(&requests, 0)
+			bodies = nil
+			response, err := client.Create(account, Idempotent())
+			assert.Nil(t, err)
+			if assert.NotNil(t, response) {
+				assert.Equal(t, http.StatusCreated, response.StatusCode)
+			}
+			if assert.Len(t, bodies, 2) {
+				assert.NotEmpty(t, bodies[0])
+				assert.Equal(t, bodies[0], bodies[1])
+			}
+		}
+	}
+}
+
+func TestRetry_PerCallPolicyOverridesClientPolicy(t *testing.T) {
+	var requests int32
+	server := newFlakyServer(1, http.StatusBadGateway, http.StatusNoContent, &requests, nil)
+	defer server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: fastRetryPolicy})
+	if assert.Nil(t, err) {
+		_, err := client.Delete("some-id", 0, WithRetryPolicy(NoRetry))
+		assert.NotNil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	}
+}
+
+func TestRetry_NetworkErrorsReturnRetryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: fastRetryPolicy})
+	if assert.Nil(t, err) {
+		_, err := client.Fetch("some-id")
+		var retryError *RetryError
+		if assert.True(t, errors.As(err, &retryError)) {
+			assert.Equal(t, 3, retryError.Attempts)
+			assert.NotNil(t, retryError.Err)
+		}
+	}
+}
+
+func TestExponentialBackoff_DelayIsBetweenZeroAndCap(t *testing.T) {
+	policy := &ExponentialBackoff{MaxAttempts: 10, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	response := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	for attempt := 1; attempt < 10; attempt++ {
+		delay, retry := policy.Retry(attempt, nil, response, nil)
+		assert.True(t, retry)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, 50*time.Millisecond)
+		if attempt == 1 {
+			assert.LessOrEqual(t, delay, 10*time.Millisecond)
+		}
+	}
+	_, retry := policy.Retry(10, nil, response, nil)
+	assert.False(t, retry)
+}
+
+func TestExponentialBackoff_HonoursRetryAfter(t *testing.T) {
+	policy := &ExponentialBackoff{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Minute}
+
+	response := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"7"}}}
+	delay, retry := policy.Retry(1, nil, response, nil)
+	assert.True(t, retry)
+	assert.Equal(t, 7*time.Second, delay)
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	response = &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": {date}}}
+	delay, retry = policy.Retry(1, nil, response, nil)
+	assert.True(t, retry)
+	assert.Greater(t, delay, 50*time.Second)
+}
+
+func TestExponentialBackoff_DoesNotRetryWhenRetryAfterExceedsMaxDelay(t *testing.T) {
+	policy := &ExponentialBackoff{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second}
+
+	response := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"86400"}}}
+	_, retry := policy.Retry(1, nil, response, nil)
+	assert.False(t, retry)
+
+	response = &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"5"}}}
+	delay, retry := policy.Retry(1, nil, response, nil)
+	assert.True(t, retry)
+	assert.Equal(t, 5*time.Second, delay)
+}
@@ -0,0 +1,130 @@
+package accounts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"log"
+	"net/http"
+)
+
+// VersionMismatchError is returned by Update and Delete when the given version is not the current version of the account,
+// meaning the account was modified since it was fetched. It wraps the *APIError of the conflict response,
+// so errors.Is(err, ErrConflict) also matches it.
+type VersionMismatchError struct {
+	AccountID string
+	Version   int64
+	Err       *APIError
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("accounts: version %d of account %s is not the current version: %v", e.Version, e.AccountID, e.Err)
+}
+
+func (e *VersionMismatchError) Unwrap() error {
+	return e.Err
+}
+
+// StatusTransitionError is returned by Update when the patch changes the status of the account to one that is
+// not allowed from its current status by models.AllowedTransition. The patch is not sent.
+type StatusTransitionError struct {
+	AccountID string
+	From      models.AccountStatus
+	To        models.AccountStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("accounts: status of account %s cannot change from %s to %s", e.AccountID, e.From, e.To)
+}
+
+// Update sends a patch to the fake API to modify an account, using the DefaultClient.
+func Update(patch *models.Account, options ...RequestOption) (*models.Account, error) {
+	return DefaultClient.Update(patch, options...)
+}
+
+// UpdateContext is like Update but uses ctx for the request.
+func UpdateContext(ctx context.Context, patch *models.Account, options ...RequestOption) (*models.Account, error) {
+	return DefaultClient.UpdateContext(ctx, patch, options...)
+}
+
+// Update sends a patch to the API to modify an account and returns the updated account, including its new version.
+// The patch is usually built with builder.NewAccountPatchBuilder: it must contain the ID of the account,
+// the version it is based on and only the attributes to change.
+// When the patch changes the status, the account is fetched first and the error is a *StatusTransitionError if
+// models.AllowedTransition does not allow the change. Since the patch is only applied to the version it is based on,
+// the status it is checked against is the one the patch applies to.
+// If the version is not the current one, the error is a *VersionMismatchError. For any other unexpected status code,
+// the error is an *APIError. Failed updates are only retried when the Idempotent option is given, since a patch
+// applied by the API whose response is lost would otherwise be sent again and rejected as a version mismatch.
+func (c *AccountsClient) Update(patch *models.Account, options ...RequestOption) (*models.Account, error) {
+	return c.UpdateContext(context.Background(), patch, options...)
+}
+
+// UpdateContext is like Update but uses ctx for the request. If ctx is cancelled or its deadline is exceeded
+// before the response is read, ctx.Err() is returned.
+func (c *AccountsClient) UpdateContext(ctx context.Context, patch *models.Account, options ...RequestOption) (*models.Account, error) {
+	if patch == nil || patch.Data == nil || patch.Data.ID == "" || patch.Data.Version == nil {
+		return nil, errors.New("accounts: patch must contain the account ID and version")
+	}
+	if err := c.checkStatusTransition(ctx, patch); err != nil {
+		return nil, err
+	}
+	requestOptions := newRequestOptions(options)
+	var updateAccountURL = c.accountURL(patch.Data.ID)
+
+	// Convert patch data to json
+	marshalledPatch, err := json.Marshal(patch)
+	if err != nil {
+		log.Println("Error marshalling patch data:", err)
+		return nil, err
+	}
+	// Build request
+	request, err := c.newRequest(ctx, OperationUpdate, http.MethodPatch, updateAccountURL, bytes.NewReader(marshalledPatch))
+	if err != nil {
+		log.Println("Error found while building update account request:", err)
+		return nil, err
+	}
+	// Update account
+	response, accountJSON, err := c.do(request, requestOptions)
+
+	// Process response
+	if err != nil {
+		log.Println("Error found while updating account:", err)
+		return nil, err
+	}
+	if response.StatusCode == http.StatusConflict {
+		log.Println("Response returned version conflict for account:", patch.Data.ID)
+		return nil, &VersionMismatchError{AccountID: patch.Data.ID, Version: *patch.Data.Version, Err: newAPIError(response, accountJSON)}
+	}
+	if response.StatusCode != http.StatusOK {
+		log.Println("Response returned error status code:", response.StatusCode)
+		return nil, newAPIError(response, accountJSON)
+	}
+
+	return c.decodeAccount(accountJSON)
+}
+
+// checkStatusTransition fetches the account updated by patch, if patch changes its status, and returns a
+// *StatusTransitionError if the status cannot change to the one of the patch.
+func (c *AccountsClient) checkStatusTransition(ctx context.Context, patch *models.Account) error {
+	if patch.Data.Attributes == nil || patch.Data.Attributes.Status == nil {
+		return nil
+	}
+	account, err := c.FetchContext(ctx, patch.Data.ID)
+	if err != nil {
+		log.Println("Error found while fetching status of account:", err)
+		return err
+	}
+	if account.Data.Attributes == nil || account.Data.Attributes.Status == nil {
+		return nil
+	}
+	from, to := *account.Data.Attributes.Status, *patch.Data.Attributes.Status
+	if !models.AllowedTransition(from, to) {
+		log.Println("Status transition not allowed for account:", patch.Data.ID)
+		return &StatusTransitionError{AccountID: patch.Data.ID, From: from, To: to}
+	}
+	return nil
+}
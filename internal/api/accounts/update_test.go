@@ -0,0 +1,205 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"github.com/nambroa/interview-accountapi/internal/models/builder"
+	uuid "github.com/nu7hatch/gouuid"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newVersionedServer serves a single account whose current version is currentVersion, answering PATCH requests
+// the way the account API does.
+func newVersionedServer(t *testing.T, account *models.Account, currentVersion int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/v1/organisation/accounts/"+account.Data.ID, r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		var patch models.Account
+		if err := json.Unmarshal(body, &patch); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if *patch.Data.Version != currentVersion {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error_message":"invalid version"}`))
+			return
+		}
+		newVersion := currentVersion + 1
+		account.Data.Version = &newVersion
+		account.Data.Attributes.SecondaryIdentification = patch.Data.Attributes.SecondaryIdentification
+		json.NewEncoder(w).Encode(account)
+	}))
+}
+
+func TestUpdate_WithCurrentVersionReturnsUpdatedAccount(t *testing.T) {
+	account, err := newTestAccount()
+	if assert.Nil(t, err) {
+		server := newVersionedServer(t, account, 0)
+		defer server.Close()
+		client, err := NewAccountsClient(Config{BaseURL: server.URL})
+		if assert.Nil(t, err) {
+			patch, err := builder.NewAccountPatchBuilder(account.Data.ID, 0).WithSecondaryIdentification("Alfred").Build()
+			if assert.Nil(t, err) {
+				updatedAccount, err := client.Update(patch)
+				assert.Nil(t, err)
+				if assert.NotNil(t, updatedAccount) {
+					assert.Equal(t, int64(1), *updatedAccount.Data.Version)
+					assert.Equal(t, "Alfred", updatedAccount.Data.Attributes.SecondaryIdentification)
+				}
+			}
+		}
+	}
+}
+
+func TestUpdate_WithStaleVersionReturnsVersionMismatchError(t *testing.T) {
+	account, err := newTestAccount()
+	if assert.Nil(t, err) {
+		server := newVersionedServer(t, account, 3)
+		defer server.Close()
+		client, err := NewAccountsClient(Config{BaseURL: server.URL})
+		if assert.Nil(t, err) {
+			patch, err := builder.NewAccountPatchBuilder(account.Data.ID, 2).WithSecondaryIdentification("Alfred").Build()
+			if assert.Nil(t, err) {
+				updatedAccount, err := client.Update(patch)
+				assert.Nil(t, updatedAccount)
+				var versionMismatch *VersionMismatchError
+				if assert.True(t, errors.As(err, &versionMismatch)) {
+					assert.Equal(t, account.Data.ID, versionMismatch.AccountID)
+					assert.Equal(t, int64(2), versionMismatch.Version)
+				}
+				assert.ErrorIs(t, err, ErrConflict)
+			}
+		}
+	}
+}
+
+// newLostResponseServer applies the patches it receives to the account but answers the first one with 502 Bad Gateway,
+// as when the response is lost on its way back. Patches of an earlier version are answered with 409 Conflict.
+func newLostResponseServer(account *models.Account, patches *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var patch models.Account
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if *patch.Data.Version != *account.Data.Version {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		newVersion := *account.Data.Version + 1
+		account.Data.Version = &newVersion
+		if atomic.AddInt32(patches, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(account)
+	}))
+}
+
+func TestUpdate_IsNotRetriedUnlessIdempotent(t *testing.T) {
+	account, err := newTestAccount()
+	if assert.Nil(t, err) {
+		var patches int32
+		server := newLostResponseServer(account, &patches)
+		defer server.Close()
+		client, err := NewAccountsClient(Config{BaseURL: server.URL, RetryPolicy: fastRetryPolicy})
+		if assert.Nil(t, err) {
+			patch, err := builder.NewAccountPatchBuilder(account.Data.ID, 0).WithSecondaryIdentification("Alfred").Build()
+			if assert.Nil(t, err) {
+				updatedAccount, err := client.Update(patch)
+				assert.Nil(t, updatedAccount)
+				var apiError *APIError
+				if assert.ErrorAs(t, err, &apiError) {
+					assert.Equal(t, http.StatusBadGateway, apiError.StatusCode)
+				}
+				var versionMismatch *VersionMismatchError
+				assert.False(t, errors.As(err, &versionMismatch))
+				assert.Equal(t, int32(1), atomic.LoadInt32(&patches))
+			}
+		}
+	}
+}
+
+func TestUpdate_WithoutVersionReturnsError(t *testing.T) {
+	ID, _ := uuid.NewV4()
+	updatedAccount, err := Update(&models.Account{Data: &models.AccountData{ID: ID.String()}})
+	assert.NotNil(t, err)
+	assert.Nil(t, updatedAccount)
+}
+
+// newAccountStatusServer serves a single account with the given status, applying the status of the patches it receives.
+func newAccountStatusServer(account *models.Account, status models.AccountStatus, patches *int32) *httptest.Server {
+	account.Data.Attributes.Status = &status
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(account)
+		case http.MethodPatch:
+			atomic.AddInt32(patches, 1)
+			var patch models.Account
+			if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			account.Data.Attributes.Status = patch.Data.Attributes.Status
+			json.NewEncoder(w).Encode(account)
+		}
+	}))
+}
+
+func TestUpdate_WithDisallowedStatusReturnsStatusTransitionError(t *testing.T) {
+	account, err := newTestAccount()
+	if assert.Nil(t, err) {
+		var patches int32
+		server := newAccountStatusServer(account, models.CONFIRMED, &patches)
+		defer server.Close()
+		client, err := NewAccountsClient(Config{BaseURL: server.URL})
+		if assert.Nil(t, err) {
+			failed := models.FAILED
+			patch, err := builder.NewAccountPatchBuilder(account.Data.ID, 0).WithStatus(&failed).Build()
+			if assert.Nil(t, err) {
+				updatedAccount, err := client.Update(patch)
+				assert.Nil(t, updatedAccount)
+				var transitionError *StatusTransitionError
+				if assert.ErrorAs(t, err, &transitionError) {
+					assert.Equal(t, &StatusTransitionError{AccountID: account.Data.ID, From: models.CONFIRMED, To: models.FAILED}, transitionError)
+				}
+				assert.Equal(t, int32(0), atomic.LoadInt32(&patches))
+			}
+		}
+	}
+}
+
+func TestUpdate_WithAllowedStatusReturnsUpdatedAccount(t *testing.T) {
+	account, err := newTestAccount()
+	if assert.Nil(t, err) {
+		var patches int32
+		server := newAccountStatusServer(account, models.CONFIRMED, &patches)
+		defer server.Close()
+		client, err := NewAccountsClient(Config{BaseURL: server.URL})
+		if assert.Nil(t, err) {
+			err = client.WithLatestVersion(context.Background(), account.Data.ID, func(account *models.Account) error {
+				closed := models.CLOSED
+				patch, err := builder.NewAccountPatchBuilder(account.Data.ID, *account.Data.Version).WithStatus(&closed).Build()
+				if err != nil {
+					return err
+				}
+				updatedAccount, err := client.Update(patch)
+				if assert.Nil(t, err) {
+					assert.Equal(t, models.CLOSED, *updatedAccount.Data.Attributes.Status)
+				}
+				return err
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&patches))
+		}
+	}
+}
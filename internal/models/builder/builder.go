@@ -1,9 +1,11 @@
 package builder
 
 import (
-	"encoding/json"
-	"github.com/go-playground/validator/v10"
+	"context"
 	"github.com/nambroa/interview-accountapi/internal/models"
+	"github.com/nambroa/interview-accountapi/internal/models/validation"
+	"reflect"
+	"sync/atomic"
 )
 
 // AccountBuilder represents a builder that builds Accounts. It also contains validations (inside the Account itself)
@@ -12,117 +14,226 @@ import (
 // https://www.api-docs.form3.tech/api/schemes/fps-direct/accounts/accounts/create-an-account
 // Examples: ID must be UUID, BankID must be max length 11, Bic must be 8 or 11 chars longs, Country must be 2 chars long, etc.
 
+// validate is shared by every builder since validators cache the structure of the models.
+var validate = validation.New()
+
+// translations contains the messages of the violations returned by Build, in every supported locale.
+var translations = mustRegisterTranslations()
+
+func mustRegisterTranslations() *validation.Translations {
+	translations, err := validation.RegisterTranslations(validate)
+	if err != nil {
+		panic("builder: registering validation messages: " + err.Error())
+	}
+	return translations
+}
+
+// Builders are copy-on-write: Clone returns a builder sharing the account of the original one, which is copied by
+// the first With method called on either of them. Build returns a deep copy of the account, so built accounts
+// and builders never share data. A builder can be cloned and built from several goroutines at the same time,
+// for example to use it as a template, but its With methods must not be called concurrently.
 type AccountBuilder struct {
 	account *models.Account
+	// shared is true when account may be shared with clones of the builder, and must be copied before being modified.
+	shared atomic.Bool
+	// patch is true for builders describing a partial update, where only the fields that were set are validated.
+	patch bool
+	// currentStatus is the status of the account updated by a patch, when known. The status set in the patch must be
+	// allowed from it by models.AllowedTransition.
+	currentStatus *models.AccountStatus
 }
 
 // NewAccountBuilder contains required fields according to documentation https://www.api-docs.form3.tech/api/schemes/fps-direct/accounts/accounts/create-an-account
 // and testing of the API (meaning that name and type fields are required as well).
+// The other fields have the defaults of models.NewAccount. See FromOptions for a constructor with typed arguments.
 func NewAccountBuilder(ID, organisationID, bankID, bankIDCode, bic, country string, names []string) *AccountBuilder {
-	// Defaults are selected according to the docs https://www.api-docs.form3.tech/api/schemes/fps-direct/accounts/accounts/create-an-account
-	var defaultAccountClassification = models.PERSONAL
-	var defaultJointAccount = false
-	var defaultVersion int64 = 0
-	var defaultNMS = models.SUPPORTED
-	var defaultBaseCurrency = "GBP"
-
-	accountAttributes := &models.AccountAttributes{
-		AccountClassification:   &defaultAccountClassification,
-		AccountNumber:           "",
-		AlternativeNames:        nil,
-		BankID:                  bankID,
-		BankIDCode:              bankIDCode,
-		BaseCurrency:            defaultBaseCurrency,
-		Bic:                     bic,
-		Country:                 &country,
-		Iban:                    "",
-		JointAccount:            &defaultJointAccount,
-		Name:                    names,
-		NameMatchingStatus:      &defaultNMS,
-		SecondaryIdentification: "",
-		Status:                  nil,
-	}
+	return FromOptions(
+		models.WithID(models.AccountID(ID)),
+		models.WithOrganisationID(models.OrganisationID(organisationID)),
+		models.WithBankID(models.BankID(bankID)),
+		models.WithBankIDCode(models.BankIDCode(bankIDCode)),
+		models.WithBIC(models.BIC(bic)),
+		models.WithCountry(models.CountryCode(country)),
+		models.WithName(names...),
+	)
+}
+
+// FromOptions creates an account builder with the account returned by models.NewAccount(opts...). It will not build the account.
+func FromOptions(opts ...models.AccountOption) *AccountBuilder {
+	return &AccountBuilder{account: models.NewAccount(opts...)}
+}
 
+// NewAccountPatchBuilder returns a builder describing a partial update of the account with the given ID, where version
+// is the version the update is based on. Unlike NewAccountBuilder, no defaults are set: only the fields set
+// through the With methods are sent to the API and validated by Build.
+func NewAccountPatchBuilder(ID string, version int64) *AccountBuilder {
 	accountData := &models.AccountData{
-		Attributes:     accountAttributes,
-		ID:             ID,
-		OrganisationID: organisationID,
-		Type:           models.ACCOUNTS,
-		Version:        &defaultVersion,
+		Attributes: &models.AccountAttributes{},
+		ID:         ID,
+		Type:       models.ACCOUNTS,
+		Version:    &version,
+	}
+
+	return &AccountBuilder{account: &models.Account{Data: accountData}, patch: true}
+}
+
+// NewAccountPatchBuilderFor is like NewAccountPatchBuilder but takes the ID and version from account, usually the
+// latest version returned by Fetch. If account has a status, Build also checks that the status set through
+// WithStatus is allowed from it by models.AllowedTransition, reporting a validation.TagStatusTransition violation otherwise.
+func NewAccountPatchBuilderFor(account *models.Account) *AccountBuilder {
+	var version int64
+	if account.Data.Version != nil {
+		version = *account.Data.Version
+	}
+	patchBuilder := NewAccountPatchBuilder(account.Data.ID, version)
+	if account.Data.Attributes != nil && account.Data.Attributes.Status != nil {
+		currentStatus := *account.Data.Attributes.Status
+		patchBuilder.currentStatus = &currentStatus
 	}
+	return patchBuilder
+}
 
-	account := &models.Account{Data: accountData}
+// Clone returns a builder building the same account as ab. Both builders can then be modified independently.
+func (ab *AccountBuilder) Clone() *AccountBuilder {
+	ab.shared.Store(true)
+	clone := &AccountBuilder{account: ab.account, patch: ab.patch, currentStatus: ab.currentStatus}
+	clone.shared.Store(true)
+	return clone
+}
 
-	return &AccountBuilder{account: account}
+// mutableAccount returns the account of the builder so it can be modified, copying it first if it is shared with clones.
+func (ab *AccountBuilder) mutableAccount() *models.Account {
+	if ab.shared.Load() {
+		ab.account = ab.account.Copy()
+		ab.shared.Store(false)
+	}
+	return ab.account
 }
 
-func (ab *AccountBuilder) WithVersion(version *int64) *AccountBuilder {
-	ab.account.Data.Version = version
+// With applies the options to the account of the builder.
+func (ab *AccountBuilder) With(opts ...models.AccountOption) *AccountBuilder {
+	account := ab.mutableAccount()
+	for _, option := range opts {
+		option(account)
+	}
 	return ab
 }
 
+// The With methods taking a pointer unset their field when given nil.
+
+func (ab *AccountBuilder) WithVersion(version *int64) *AccountBuilder {
+	if version == nil {
+		ab.mutableAccount().Data.Version = nil
+		return ab
+	}
+	return ab.With(models.WithVersion(*version))
+}
+
 func (ab *AccountBuilder) WithAccountClassification(classification *models.AccountClassification) *AccountBuilder {
-	ab.account.Data.Attributes.AccountClassification = classification
-	return ab
+	if classification == nil {
+		ab.mutableAccount().Data.Attributes.AccountClassification = nil
+		return ab
+	}
+	return ab.With(models.WithAccountClassification(*classification))
 }
 
 func (ab *AccountBuilder) WithNameMatchingStatus(matching *models.NameMatchingStatus) *AccountBuilder {
-	ab.account.Data.Attributes.NameMatchingStatus = matching
-	return ab
+	if matching == nil {
+		ab.mutableAccount().Data.Attributes.NameMatchingStatus = nil
+		return ab
+	}
+	return ab.With(models.WithNameMatchingStatus(*matching))
 }
 
 func (ab *AccountBuilder) WithAccountNumber(number string) *AccountBuilder {
-	ab.account.Data.Attributes.AccountNumber = number
-	return ab
+	return ab.With(models.WithAccountNumber(number))
 }
 
 func (ab *AccountBuilder) WithAlternativeNames(alternativeNames []string) *AccountBuilder {
-	ab.account.Data.Attributes.AlternativeNames = alternativeNames
-	return ab
+	return ab.With(models.WithAlternativeNames(alternativeNames...))
 }
 
 func (ab *AccountBuilder) WithBaseCurrency(baseCurrency string) *AccountBuilder {
-	ab.account.Data.Attributes.BaseCurrency = baseCurrency
-	return ab
+	return ab.With(models.WithBaseCurrency(baseCurrency))
 }
 
 func (ab *AccountBuilder) WithIban(iban string) *AccountBuilder {
-	ab.account.Data.Attributes.Iban = iban
-	return ab
+	return ab.With(models.WithIban(iban))
 }
 
 func (ab *AccountBuilder) WithJointAccount(jointAccount *bool) *AccountBuilder {
-	ab.account.Data.Attributes.JointAccount = jointAccount
-	return ab
+	if jointAccount == nil {
+		ab.mutableAccount().Data.Attributes.JointAccount = nil
+		return ab
+	}
+	return ab.With(models.WithJointAccount(*jointAccount))
 }
 
 func (ab *AccountBuilder) WithSecondaryIdentification(secondaryIdentification string) *AccountBuilder {
-	ab.account.Data.Attributes.SecondaryIdentification = secondaryIdentification
-	return ab
+	return ab.With(models.WithSecondaryIdentification(secondaryIdentification))
 }
 
 func (ab *AccountBuilder) WithStatus(status *models.AccountStatus) *AccountBuilder {
-	ab.account.Data.Attributes.Status = status
-	return ab
+	if status == nil {
+		ab.mutableAccount().Data.Attributes.Status = nil
+		return ab
+	}
+	return ab.With(models.WithStatus(*status))
 }
 
-// Build validates the account inside the builder and returns it alongside validation data.
+// Build validates the account inside the builder and returns a deep copy of it alongside validation data.
+// For patch builders, only the identification of the account and the attributes that were set are validated, field by field.
+// If the account is invalid, the error is a *ValidationError whose messages are in English.
 func (ab *AccountBuilder) Build() (*models.Account, error) {
-	validate := validator.New()
-	err := validate.Struct(ab.account)
+	return ab.BuildWithLocale(validation.English)
+}
+
+// BuildWithLocale is like Build but the messages of the violations are in the given locale, like validation.Spanish
+// or "fr-CA". Unsupported locales fall back to English.
+func (ab *AccountBuilder) BuildWithLocale(locale string) (*models.Account, error) {
+	return ab.build(context.Background(), locale)
+}
+
+// BuildReceived is like Build for accounts returned by the API: only the rules of each field are validated, not the
+// rules involving several attributes (country rules, UK modulus check and BIC country), so accounts stored under
+// earlier rules can still be read, updated and deleted.
+func (ab *AccountBuilder) BuildReceived() (*models.Account, error) {
+	return ab.build(validation.FieldsOnly(context.Background()), validation.English)
+}
+
+func (ab *AccountBuilder) build(ctx context.Context, locale string) (*models.Account, error) {
+	if ab.currentStatus != nil {
+		ctx = validation.WithCurrentStatus(ctx, *ab.currentStatus)
+	}
+	var err error
+	if ab.patch {
+		// A patch does not hold the whole account, so the rules involving several attributes cannot be checked.
+		err = validate.StructPartialCtx(validation.FieldsOnly(ctx), ab.account, patchFields(ab.account)...)
+	} else {
+		err = validate.StructCtx(ctx, ab.account)
+	}
 	if err != nil {
-		return nil, err
+		return nil, newValidationError(err, translations.Translator(locale))
 	}
 
-	return ab.account, nil
+	return ab.account.Copy(), nil
+}
+
+// patchFields returns the namespaces of the fields of a patch that must be validated: the ID, type and version
+// of the account and every attribute that is set.
+func patchFields(account *models.Account) []string {
+	fields := []string{"Data.ID", "Data.Type", "Data.Version"}
+	attributes := reflect.ValueOf(account.Data.Attributes).Elem()
+	for i := 0; i < attributes.NumField(); i++ {
+		if !attributes.Field(i).IsZero() {
+			fields = append(fields, "Data.Attributes."+attributes.Type().Field(i).Name)
+		}
+	}
+	return fields
 }
 
 // FromJSON Creates an account builder with an account marshalled from the json byte array. It will not build the account.
+// Fields unknown to the models are dropped, see FromJSONWithOptions to keep them.
 func FromJSON(accountJSON []byte) (*AccountBuilder, error) {
-	var account models.Account
-	err := json.Unmarshal(accountJSON, &account)
-	if err != nil {
-		return nil, err
-	}
-	return &AccountBuilder{account: &account}, nil
+	return FromJSONWithOptions(accountJSON, DecodeOptions{})
 }
@@ -1,10 +1,17 @@
 package builder
 
 import (
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
 	"github.com/nambroa/interview-accountapi/internal/models"
+	"github.com/nambroa/interview-accountapi/internal/models/validation"
 	uuid "github.com/nu7hatch/gouuid"
 	"github.com/stretchr/testify/assert"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestAccountBuilder_ValidBasicAccount(t *testing.T) {
@@ -62,8 +69,8 @@ func TestAccountBuilder_ValidFullAccount(t *testing.T) {
 	var accClass = models.BUSINESS
 	var NMS = models.OPTED_OUT
 	var version int64 = 1
-	var iban = "AB12AZ33"
-	var accNumber = "1234567890"
+	var iban = "GB33BUKB20201555555555"
+	var accNumber = "12345678"
 	var secondaryId = "Alfred"
 	var pesoCurr = "ARS"
 
@@ -217,6 +224,26 @@ func TestAccountBuilder_WithBicLengthNotAlphanumReturnsValidationError(t *testin
 	assert.NotNil(t, err)
 }
 
+func TestAccountBuilder_WithBicOfAnotherCountryReturnsValidationError(t *testing.T) {
+	// Basic Builder fields
+	ID, _ := uuid.NewV4()
+	OrganisationID, _ := uuid.NewV4()
+	var names []string
+	names = append(names, "Batman")
+
+	// Basic Builder (BIC of a German bank for a British account)
+	var accountBuilder = NewAccountBuilder(ID.String(), OrganisationID.String(), "400300", "GBDSC",
+		"DEUTDEFF500", "GB", names)
+	// Create Account
+	_, err := accountBuilder.Build()
+
+	// Validate error thrown
+	var validationErrors validator.ValidationErrors
+	if assert.ErrorAs(t, err, &validationErrors) {
+		assert.Equal(t, "bic_matches_country", validationErrors[0].Tag())
+	}
+}
+
 func TestAccountBuilder_WithCountryCodeNotISO3166ReturnsValidationError(t *testing.T) {
 	// Basic Builder fields
 	ID, _ := uuid.NewV4()
@@ -369,6 +396,32 @@ func TestAccountBuilder_WithAccountNumberNotAlphanumReturnsValidationError(t *te
 	assert.NotNil(t, err)
 }
 
+func TestAccountBuilder_WithAccountNumberFailingModulusCheckReturnsValidationError(t *testing.T) {
+	// Basic Builder fields
+	ID, _ := uuid.NewV4()
+	OrganisationID, _ := uuid.NewV4()
+	var names []string
+	names = append(names, "Batman")
+
+	// Basic Builder (test vector of the Vocalink modulus checking specification failing the modulus 11 check)
+	var accountBuilder = NewAccountBuilder(ID.String(), OrganisationID.String(), "107999", "GBDSC",
+		"NWBKGB22", "GB", names)
+	accountBuilder.WithAccountNumber("88837493")
+	// Create Account
+	_, err := accountBuilder.Build()
+
+	// Validate error thrown
+	var validationErrors validator.ValidationErrors
+	if assert.ErrorAs(t, err, &validationErrors) {
+		assert.Equal(t, "uk_modulus", validationErrors[0].Tag())
+	}
+
+	// Validate the valid account number of the same test vector
+	accountBuilder.WithAccountNumber("88837491")
+	_, err = accountBuilder.Build()
+	assert.Nil(t, err)
+}
+
 func TestAccountBuilder_WithAccountNumberLongerThan64ReturnsValidationError(t *testing.T) {
 	// Basic Builder fields
 	ID, _ := uuid.NewV4()
@@ -386,3 +439,494 @@ func TestAccountBuilder_WithAccountNumberLongerThan64ReturnsValidationError(t *t
 	// Validate error thrown
 	assert.NotNil(t, err)
 }
+
+func TestAccountBuilder_WithIbanWithWrongCheckDigitsReturnsValidationError(t *testing.T) {
+	// Basic Builder fields
+	ID, _ := uuid.NewV4()
+	OrganisationID, _ := uuid.NewV4()
+	var names []string
+	names = append(names, "Batman")
+
+	// Basic Builder
+	var accountBuilder = NewAccountBuilder(ID.String(), OrganisationID.String(), "400300", "GBDSC",
+		"NWBKGB22", "GB", names)
+	accountBuilder.WithIban("GB34BUKB20201555555555")
+	// Create Account
+	_, err := accountBuilder.Build()
+
+	// Validate error thrown
+	var validationErrors validator.ValidationErrors
+	if assert.ErrorAs(t, err, &validationErrors) {
+		assert.Equal(t, "iban", validationErrors[0].Tag())
+	}
+}
+
+func TestAccountBuilder_InvalidAccountReturnsViolationsWithJSONPaths(t *testing.T) {
+	// Basic Builder fields
+	ID, _ := uuid.NewV4()
+	OrganisationID, _ := uuid.NewV4()
+	var names []string
+	names = append(names, "Batman", "")
+
+	// Basic Builder (BIC with an unknown country, second name empty)
+	var accountBuilder = NewAccountBuilder(ID.String(), OrganisationID.String(), "400300", "GBDSC",
+		"NWBKZZ22", "GB", names)
+	accountBuilder.WithSecondaryIdentification(strings.Repeat("A", 141))
+	// Create Account
+	_, err := accountBuilder.Build()
+
+	// Validate violations
+	var validationError *ValidationError
+	if assert.ErrorAs(t, err, &validationError) {
+		assert.Equal(t, []Violation{
+			{Path: "data.attributes.bic", Rule: "bic_country", Value: "NWBKZZ22",
+				Message: "bic must contain a valid country code after the institution code"},
+			{Path: "data.attributes.name[1]", Rule: "min", Param: "1", Value: "",
+				Message: "name[1] must be at least 1 character in length"},
+			{Path: "data.attributes.secondary_identification", Rule: "max", Param: "140", Value: strings.Repeat("A", 141),
+				Message: "secondary_identification must be a maximum of 140 characters in length"},
+		}, validationError.Violations)
+		assert.Equal(t, "builder: invalid account: data.attributes.bic: bic_country; data.attributes.name[1]: min=1; "+
+			"data.attributes.secondary_identification: max=140", validationError.Error())
+	}
+	// Validate the validator errors are still available
+	var validationErrors validator.ValidationErrors
+	assert.ErrorAs(t, err, &validationErrors)
+}
+
+func TestValidationError_MarshalsViolations(t *testing.T) {
+	// Basic Builder (patch with an invalid base currency)
+	_, err := NewAccountPatchBuilder("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0).WithBaseCurrency("XXXX").Build()
+
+	var validationError *ValidationError
+	if assert.ErrorAs(t, err, &validationError) {
+		marshalledError, err := json.Marshal(validationError)
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"violations": [{"path": "data.attributes.base_currency", "rule": "iso4217", "value": "XXXX",
+			"message": "base_currency must be a valid ISO 4217 currency code"}]}`, string(marshalledError))
+	}
+}
+
+func TestAccountBuilder_BuildWithLocaleTranslatesMessages(t *testing.T) {
+	// Basic Builder fields
+	ID, _ := uuid.NewV4()
+	OrganisationID, _ := uuid.NewV4()
+	var names []string
+	names = append(names, "Batman")
+
+	// Basic Builder (BIC of a German bank, account number failing the modulus check)
+	var accountBuilder = NewAccountBuilder(ID.String(), OrganisationID.String(), "089999", "GBDSC",
+		"DEUTDEFF", "GB", names)
+	accountBuilder.WithAccountNumber("66374959")
+
+	tests := []struct {
+		locale   string
+		messages []string
+	}{
+		{validation.English, []string{
+			"bic must belong to a bank of GB",
+			"account_number is not a valid account number for sort code 089999",
+		}},
+		{validation.Spanish, []string{
+			"bic debe pertenecer a un banco de GB",
+			"account_number no es un número de cuenta válido para el sort code 089999",
+		}},
+		{validation.French, []string{
+			"bic doit appartenir à une banque de GB",
+			"account_number n'est pas un numéro de compte valide pour le sort code 089999",
+		}},
+		{"fr-CA", []string{
+			"bic doit appartenir à une banque de GB",
+			"account_number n'est pas un numéro de compte valide pour le sort code 089999",
+		}},
+		{"de", []string{
+			"bic must belong to a bank of GB",
+			"account_number is not a valid account number for sort code 089999",
+		}},
+	}
+	for _, test := range tests {
+		_, err := accountBuilder.BuildWithLocale(test.locale)
+
+		var validationError *ValidationError
+		if assert.ErrorAs(t, err, &validationError, test.locale) {
+			var messages []string
+			for _, violation := range validationError.Violations {
+				messages = append(messages, violation.Message)
+			}
+			assert.ElementsMatch(t, test.messages, messages, test.locale)
+		}
+	}
+}
+
+func TestFromOptions_BuildsSameAccountAsNewAccountBuilder(t *testing.T) {
+	ID, _ := uuid.NewV4()
+	OrganisationID, _ := uuid.NewV4()
+
+	account, err := FromOptions(
+		models.WithID(models.AccountID(ID.String())),
+		models.WithOrganisationID(models.OrganisationID(OrganisationID.String())),
+		models.WithBankID("400300"),
+		models.WithBankIDCode("GBDSC"),
+		models.WithBIC("NWBKGB22"),
+		models.WithCountry("GB"),
+		models.WithName("Batman"),
+	).WithAccountNumber("41920716").Build()
+	if assert.Nil(t, err) {
+		expectedAccount, err := NewAccountBuilder(ID.String(), OrganisationID.String(), "400300", "GBDSC", "NWBKGB22", "GB",
+			[]string{"Batman"}).WithAccountNumber("41920716").Build()
+		if assert.Nil(t, err) {
+			assert.Equal(t, expectedAccount, account)
+		}
+	}
+}
+
+func TestFromOptions_InvalidAccountReturnsValidationError(t *testing.T) {
+	// Missing organisation ID, bank ID code and name
+	_, err := FromOptions(models.WithID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"), models.WithBankID("400300"),
+		models.WithBIC("NWBKGB22"), models.WithCountry("GB")).Build()
+
+	var validationError *ValidationError
+	if assert.ErrorAs(t, err, &validationError) {
+		var paths []string
+		for _, violation := range validationError.Violations {
+			paths = append(paths, violation.Path)
+		}
+		assert.ElementsMatch(t, []string{"data.organisation_id", "data.attributes.name", "data.attributes.bank_id_code"}, paths)
+	}
+}
+
+func TestAccountBuilder_WithAppliesOptions(t *testing.T) {
+	accountBuilder := newTemplateBuilder()
+	clone := accountBuilder.Clone().With(models.WithIban("GB33BUKB20201555555555"), models.WithStatus(models.CONFIRMED))
+
+	account, err := clone.Build()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "GB33BUKB20201555555555", account.Data.Attributes.Iban)
+		assert.Equal(t, models.CONFIRMED, *account.Data.Attributes.Status)
+	}
+	templateAccount, err := accountBuilder.Build()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "", templateAccount.Data.Attributes.Iban)
+		assert.Nil(t, templateAccount.Data.Attributes.Status)
+	}
+}
+
+func TestAccountBuilder_BuildReceivedOnlyValidatesFields(t *testing.T) {
+	// Account number breaking the GB rules, but not the rules of the field
+	accountBuilder := newTemplateBuilder().WithAccountNumber("1234567890")
+
+	_, err := accountBuilder.Build()
+	var validationError *ValidationError
+	if assert.ErrorAs(t, err, &validationError) {
+		assert.Equal(t, validation.TagCountryLength, validationError.Violations[0].Rule)
+	}
+	account, err := accountBuilder.BuildReceived()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "1234567890", account.Data.Attributes.AccountNumber)
+	}
+
+	// Rules of the fields are still validated
+	_, err = accountBuilder.WithAccountNumber("1234-5678").BuildReceived()
+	assert.ErrorAs(t, err, &validationError)
+}
+
+// newTemplateBuilder returns a builder of a valid account, used as a template by the tests of Clone.
+func newTemplateBuilder() *AccountBuilder {
+	ID, _ := uuid.NewV4()
+	OrganisationID, _ := uuid.NewV4()
+	return NewAccountBuilder(ID.String(), OrganisationID.String(), "400300", "GBDSC", "NWBKGB22", "GB", []string{"Batman"})
+}
+
+func TestAccountBuilder_BuildReturnsCopies(t *testing.T) {
+	accountBuilder := newTemplateBuilder()
+
+	account, err := accountBuilder.Build()
+	if assert.Nil(t, err) {
+		account.Data.Attributes.Name[0] = "Joker"
+		*account.Data.Attributes.Country = "FR"
+		*account.Data.Version = 5
+
+		otherAccount, err := accountBuilder.Build()
+		if assert.Nil(t, err) {
+			assert.NotSame(t, account, otherAccount)
+			assert.Equal(t, []string{"Batman"}, otherAccount.Data.Attributes.Name)
+			assert.Equal(t, "GB", *otherAccount.Data.Attributes.Country)
+			assert.Equal(t, int64(0), *otherAccount.Data.Version)
+		}
+	}
+}
+
+func TestAccountBuilder_WithMethodsCopyArguments(t *testing.T) {
+	names := []string{"Batman"}
+	alternativeNames := []string{"Bruce"}
+	status := models.PENDING
+	accountBuilder := NewAccountBuilder("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+		"400300", "GBDSC", "NWBKGB22", "GB", names)
+	accountBuilder.WithAlternativeNames(alternativeNames).WithStatus(&status)
+
+	names[0], alternativeNames[0], status = "Joker", "Harley", models.CLOSED
+
+	account, err := accountBuilder.Build()
+	if assert.Nil(t, err) {
+		assert.Equal(t, []string{"Batman"}, account.Data.Attributes.Name)
+		assert.Equal(t, []string{"Bruce"}, account.Data.Attributes.AlternativeNames)
+		assert.Equal(t, models.PENDING, *account.Data.Attributes.Status)
+	}
+}
+
+func TestAccountBuilder_CloneIsIndependent(t *testing.T) {
+	template := newTemplateBuilder().WithSecondaryIdentification("Alfred")
+	clone := template.Clone().WithSecondaryIdentification("Robin").WithAlternativeNames([]string{"Bruce"})
+	template.WithAccountNumber("41920716")
+
+	templateAccount, err := template.Build()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "Alfred", templateAccount.Data.Attributes.SecondaryIdentification)
+		assert.Nil(t, templateAccount.Data.Attributes.AlternativeNames)
+		assert.Equal(t, "41920716", templateAccount.Data.Attributes.AccountNumber)
+	}
+	cloneAccount, err := clone.Build()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "Robin", cloneAccount.Data.Attributes.SecondaryIdentification)
+		assert.Equal(t, []string{"Bruce"}, cloneAccount.Data.Attributes.AlternativeNames)
+		assert.Equal(t, "", cloneAccount.Data.Attributes.AccountNumber)
+		assert.Equal(t, templateAccount.Data.ID, cloneAccount.Data.ID)
+	}
+}
+
+// TestAccountBuilder_ParallelBuildsFromSharedTemplate is meant to be run with the race detector (go test -race).
+func TestAccountBuilder_ParallelBuildsFromSharedTemplate(t *testing.T) {
+	template := newTemplateBuilder().WithAlternativeNames([]string{"Bruce"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			secondaryIdentification := "customer-" + strconv.Itoa(i)
+			account, err := template.Clone().WithSecondaryIdentification(secondaryIdentification).Build()
+			if assert.Nil(t, err) {
+				assert.Equal(t, secondaryIdentification, account.Data.Attributes.SecondaryIdentification)
+				account.Data.Attributes.AlternativeNames[0] = secondaryIdentification
+			}
+			templateAccount, err := template.Build()
+			if assert.Nil(t, err) {
+				assert.Equal(t, "", templateAccount.Data.Attributes.SecondaryIdentification)
+				assert.Equal(t, []string{"Bruce"}, templateAccount.Data.Attributes.AlternativeNames)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestAccountPatchBuilder_OnlyContainsSetFields(t *testing.T) {
+	ID, _ := uuid.NewV4()
+	var status = models.CONFIRMED
+
+	// Patch Builder
+	var patchBuilder = NewAccountPatchBuilder(ID.String(), 2)
+	patchBuilder.WithSecondaryIdentification("Alfred")
+	patchBuilder.WithStatus(&status)
+	// Create Patch
+	patch, err := patchBuilder.Build()
+	// Validate no errors thrown
+	assert.Nil(t, err)
+
+	// Validate patch fields
+	marshalledPatch, err := json.Marshal(patch)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"data":{"id":"`+ID.String()+`","type":"accounts","version":2,
+		"attributes":{"secondary_identification":"Alfred","status":"confirmed"}}}`, string(marshalledPatch))
+}
+
+func TestAccountPatchBuilder_WithInvalidSetFieldReturnsValidationError(t *testing.T) {
+	ID, _ := uuid.NewV4()
+
+	// Patch Builder
+	var patchBuilder = NewAccountPatchBuilder(ID.String(), 0)
+	patchBuilder.WithAccountNumber("*&#@!*&$!@(*$@!*$@*(@$!*")
+	// Create Patch
+	_, err := patchBuilder.Build()
+
+	// Validate error thrown
+	assert.NotNil(t, err)
+}
+
+func TestAccountPatchBuilder_DoesNotApplyCountryRulesToFieldsNotSet(t *testing.T) {
+	ID, _ := uuid.NewV4()
+
+	// Patch Builder (the GB rules require a bank ID, bank ID code and BIC)
+	patch, err := NewAccountPatchBuilder(ID.String(), 0).With(models.WithCountry("GB")).Build()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "GB", *patch.Data.Attributes.Country)
+	}
+}
+
+func TestAccountPatchBuilder_WithIDNotUUIDReturnsValidationError(t *testing.T) {
+	// Patch Builder
+	var patchBuilder = NewAccountPatchBuilder("not-uuid", 0)
+	patchBuilder.WithSecondaryIdentification("Alfred")
+	// Create Patch
+	_, err := patchBuilder.Build()
+
+	// Validate error thrown
+	assert.NotNil(t, err)
+}
+
+func TestAccountPatchBuilderFor_WithDisallowedStatusReturnsValidationError(t *testing.T) {
+	ID, _ := uuid.NewV4()
+	var version int64 = 3
+	var confirmed, failed = models.CONFIRMED, models.FAILED
+	account := &models.Account{Data: &models.AccountData{
+		Attributes: &models.AccountAttributes{Status: &confirmed},
+		ID:         ID.String(),
+		Version:    &version,
+	}}
+
+	// Patch Builder (confirmed accounts cannot fail)
+	_, err := NewAccountPatchBuilderFor(account).WithStatus(&failed).BuildWithLocale(validation.Spanish)
+
+	var validationError *ValidationError
+	if assert.ErrorAs(t, err, &validationError) {
+		assert.Equal(t, []Violation{{Path: "data.attributes.status", Rule: validation.TagStatusTransition, Param: "confirmed",
+			Value: models.FAILED, Message: "status no puede cambiar de confirmed a failed"}}, validationError.Violations)
+	}
+}
+
+func TestAccountPatchBuilderFor_WithAllowedStatusReturnsPatch(t *testing.T) {
+	ID, _ := uuid.NewV4()
+	var version int64 = 3
+	var confirmed, closed = models.CONFIRMED, models.CLOSED
+	account := &models.Account{Data: &models.AccountData{
+		Attributes: &models.AccountAttributes{Status: &confirmed},
+		ID:         ID.String(),
+		Version:    &version,
+	}}
+
+	// Patch Builder (confirmed accounts can be closed)
+	patch, err := NewAccountPatchBuilderFor(account).WithStatus(&closed).Build()
+
+	if assert.Nil(t, err) {
+		assert.Equal(t, ID.String(), patch.Data.ID)
+		assert.Equal(t, int64(3), *patch.Data.Version)
+		assert.Equal(t, models.CLOSED, *patch.Data.Attributes.Status)
+	}
+	// The status of the account is left untouched
+	assert.Equal(t, models.CONFIRMED, *account.Data.Attributes.Status)
+}
+
+func TestFromJSONWithOptions_StrictReportsUnknownEnumValues(t *testing.T) {
+	_, err := FromJSONWithOptions([]byte(`{"data":{"attributes":{"status":"faied"},"type":"accounts"}}`), DecodeOptions{Strict: true})
+
+	var decodeError *DecodeError
+	if assert.ErrorAs(t, err, &decodeError) && assert.Len(t, decodeError.Fields, 1) {
+		assert.Equal(t, "data.attributes.status", decodeError.Fields[0].Path)
+	}
+	_, err = FromJSON([]byte(`{"data":{"attributes":{"status":"faied"},"type":"accounts"}}`))
+	assert.ErrorIs(t, err, models.ErrUnknownValue)
+}
+
+func TestFromJSON_KeepsEnvelopeLinksMetaAndTimestamps(t *testing.T) {
+	accountJSON := []byte(`{
+		"data": {
+			"attributes": {"bank_id": "400300", "bank_id_code": "GBDSC", "base_currency": "GBP", "bic": "NWBKGB22", "country": "GB", "name": ["Batman"]},
+			"created_on": "2022-11-09T20:51:43.522Z",
+			"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+			"modified_on": "2022-11-10T08:00:00Z",
+			"organisation_id": "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+			"type": "accounts",
+			"version": 1
+		},
+		"links": {"self": "/v1/organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"},
+		"meta": {"count": 1}
+	}`)
+	// Unmarshal Account
+	accountBuilder, err := FromJSON(accountJSON)
+	assert.Nil(t, err)
+	account, err := accountBuilder.Build()
+	// Validate no errors thrown
+	assert.Nil(t, err)
+
+	// Validate envelope fields
+	assert.Equal(t, time.Date(2022, 11, 9, 20, 51, 43, 522000000, time.UTC), *account.Data.CreatedOn)
+	assert.Equal(t, time.Date(2022, 11, 10, 8, 0, 0, 0, time.UTC), *account.Data.ModifiedOn)
+	assert.Equal(t, "/v1/organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", account.Links.Self)
+	assert.JSONEq(t, "1", string(account.Meta["count"]))
+
+	// Validate marshalling is lossless
+	marshalledAccount, err := json.Marshal(account)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(accountJSON), string(marshalledAccount))
+}
+
+func TestAccountListFromJSON_LosslessKeepsUnknownFields(t *testing.T) {
+	accountListJSON := []byte(`{
+		"data": [
+			{"attributes": {"bank_id": "400300", "switched_account_details": {"bank_id": "400302"}}, "id": "first", "relationships": {}, "type": "accounts"},
+			{"attributes": {"bank_id": "400301"}, "id": "second", "type": "accounts"}
+		],
+		"links": {"self": "/v1/organisation/accounts"}
+	}`)
+	// Unmarshal accounts
+	accountList, err := AccountListFromJSON(accountListJSON, DecodeOptions{Lossless: true})
+	if assert.Nil(t, err) {
+		assert.Len(t, accountList.Data, 2)
+		assert.JSONEq(t, "{}", string(accountList.Data[0].Extra["relationships"]))
+		assert.JSONEq(t, `{"bank_id": "400302"}`, string(accountList.Data[0].Attributes.Extra["switched_account_details"]))
+		assert.Nil(t, accountList.Data[1].Extra)
+		assert.Nil(t, accountList.Data[1].Attributes.Extra)
+
+		// Validate marshalling is lossless
+		marshalledData, err := json.Marshal(accountList.Data[0])
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"attributes": {"bank_id": "400300", "switched_account_details": {"bank_id": "400302"}}, "id": "first", "relationships": {}, "type": "accounts"}`, string(marshalledData))
+	}
+}
+
+func TestFromJSONWithOptions_StrictAcceptsMarshalledAccount(t *testing.T) {
+	account, err := NewAccountBuilder("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+		"400300", "GBDSC", "NWBKGB22", "GB", []string{"Batman"}).Build()
+	assert.Nil(t, err)
+	accountJSON, err := json.Marshal(account)
+	assert.Nil(t, err)
+
+	_, err = FromJSONWithOptions(accountJSON, DecodeOptions{Strict: true})
+	assert.Nil(t, err)
+}
+
+func TestFromJSONWithOptions_StrictReportsEveryMismatchedField(t *testing.T) {
+	accountJSON := []byte(`{
+		"data": {
+			"attributes": {"bank_id": 400300, "country": "GB", "name": ["Batman", 42], "private_identification": {}},
+			"created_on": "yesterday",
+			"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+			"Type": "accounts",
+			"version": 1.5
+		},
+		"meta": {"count": 1}
+	}`)
+	// Unmarshal Account
+	accountBuilder, err := FromJSONWithOptions(accountJSON, DecodeOptions{Strict: true, Lossless: true})
+	assert.Nil(t, accountBuilder)
+
+	// Validate every field is reported with its path
+	var decodeError *DecodeError
+	if assert.ErrorAs(t, err, &decodeError) {
+		var paths []string
+		for _, field := range decodeError.Fields {
+			paths = append(paths, field.Path)
+		}
+		assert.Equal(t, []string{"data.Type", "data.attributes.bank_id", "data.attributes.name[1]",
+			"data.attributes.private_identification", "data.created_on", "data.version"}, paths)
+		assert.Equal(t, "expected string but got number", decodeError.Fields[1].Problem)
+		assert.Equal(t, "unknown field", decodeError.Fields[3].Problem)
+		assert.Equal(t, "expected integer but got number", decodeError.Fields[5].Problem)
+	}
+}
+
+func TestAccountListFromJSON_StrictReportsPathOfAccount(t *testing.T) {
+	accountListJSON := []byte(`{"data": [{"id": "first", "type": "accounts"}, {"id": "second", "relationships": {}}], "links": {"self": 1}}`)
+
+	_, err := AccountListFromJSON(accountListJSON, DecodeOptions{Strict: true})
+	assert.EqualError(t, err, "builder: JSON does not match the account models: data[1].relationships: unknown field; links.self: expected string but got number")
+}
@@ -0,0 +1,301 @@
+package builder
+
+import (
+	"encoding/json"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DecodeOptions changes how accounts are unmarshalled by FromJSONWithOptions and AccountListFromJSON.
+type DecodeOptions struct {
+	// Lossless keeps the fields unknown to the models (e.g. private_identification or relationships) in the Extra
+	// maps of AccountData and AccountAttributes, so they are sent back when the account is marshalled.
+	Lossless bool
+	// Strict makes decoding fail with a *DecodeError when the JSON contains fields unknown to the models or
+	// values of an unexpected type, which is useful to detect changes of the API early. It takes precedence over Lossless.
+	Strict bool
+}
+
+// FieldError describes a field of the decoded JSON that does not match the models.
+type FieldError struct {
+	// Path is the JSON path of the field, for example "data.attributes.bank_id".
+	Path    string
+	Problem string
+}
+
+func (e FieldError) String() string {
+	return e.Path + ": " + e.Problem
+}
+
+// DecodeError is returned when decoding in strict mode, listing every field that does not match the models.
+type DecodeError struct {
+	Fields []FieldError
+}
+
+func (e *DecodeError) Error() string {
+	problems := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		problems = append(problems, field.String())
+	}
+	return "builder: JSON does not match the account models: " + strings.Join(problems, "; ")
+}
+
+// FromJSONWithOptions is like FromJSON but unmarshals the account according to the given options.
+func FromJSONWithOptions(accountJSON []byte, options DecodeOptions) (*AccountBuilder, error) {
+	if options.Strict {
+		if err := checkStrict(accountJSON, reflect.TypeOf(models.Account{})); err != nil {
+			return nil, err
+		}
+	}
+	var account models.Account
+	err := json.Unmarshal(accountJSON, &account)
+	if err != nil {
+		return nil, err
+	}
+	if options.Lossless && !options.Strict && account.Data != nil {
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(accountJSON, &envelope); err != nil {
+			return nil, err
+		}
+		if err := keepUnknownFields(account.Data, envelope.Data); err != nil {
+			return nil, err
+		}
+	}
+	return &AccountBuilder{account: &account}, nil
+}
+
+// AccountListFromJSON unmarshals a page of accounts, as returned by the list endpoint, according to the given options.
+// Unlike FromJSONWithOptions, the accounts are not wrapped in builders since they are not meant to be validated.
+func AccountListFromJSON(accountListJSON []byte, options DecodeOptions) (*models.AccountList, error) {
+	if options.Strict {
+		if err := checkStrict(accountListJSON, reflect.TypeOf(models.AccountList{})); err != nil {
+			return nil, err
+		}
+	}
+	var accountList models.AccountList
+	err := json.Unmarshal(accountListJSON, &accountList)
+	if err != nil {
+		return nil, err
+	}
+	if options.Lossless && !options.Strict {
+		var envelope struct {
+			Data []json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(accountListJSON, &envelope); err != nil {
+			return nil, err
+		}
+		for i, accountData := range accountList.Data {
+			if accountData == nil {
+				continue
+			}
+			if err := keepUnknownFields(accountData, envelope.Data[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &accountList, nil
+}
+
+// keepUnknownFields stores the fields of dataJSON unknown to the models in the Extra maps of accountData
+// and its attributes.
+func keepUnknownFields(accountData *models.AccountData, dataJSON json.RawMessage) error {
+	extra, err := unknownFields(dataJSON, reflect.TypeOf(models.AccountData{}))
+	if err != nil {
+		return err
+	}
+	accountData.Extra = extra
+	if accountData.Attributes == nil {
+		return nil
+	}
+
+	var data struct {
+		Attributes json.RawMessage `json:"attributes"`
+	}
+	if err := json.Unmarshal(dataJSON, &data); err != nil {
+		return err
+	}
+	extra, err = unknownFields(data.Attributes, reflect.TypeOf(models.AccountAttributes{}))
+	if err != nil {
+		return err
+	}
+	accountData.Attributes.Extra = extra
+	return nil
+}
+
+// unknownFields returns the fields of the JSON object that do not match any field of the given struct type,
+// or nil if there are none.
+func unknownFields(objectJSON json.RawMessage, structType reflect.Type) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(objectJSON, &fields); err != nil {
+		return nil, err
+	}
+	knownFields := jsonFieldNames(structType)
+	var extra map[string]json.RawMessage
+	for name, value := range fields {
+		if _, known := knownFields[strings.ToLower(name)]; known {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]json.RawMessage)
+		}
+		extra[name] = value
+	}
+	return extra, nil
+}
+
+// jsonFieldNames returns the fields of the given struct type by their lower cased JSON name, the way
+// encoding/json matches them when unmarshalling.
+func jsonFieldNames(structType reflect.Type) map[string]reflect.StructField {
+	names := make(map[string]reflect.StructField, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if name, ok := jsonName(field); ok {
+			names[strings.ToLower(name)] = field
+		}
+	}
+	return names
+}
+
+// jsonName returns the JSON name of a struct field, or false if the field is never unmarshalled.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" || !field.IsExported() {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// checkStrict compares the JSON with the given type and returns a *DecodeError listing the fields that are unknown
+// to the type or have a value of another type. Unlike encoding/json, field names are matched case-sensitively.
+func checkStrict(valueJSON []byte, valueType reflect.Type) error {
+	var value json.RawMessage
+	if err := json.Unmarshal(valueJSON, &value); err != nil {
+		return err
+	}
+	var fieldErrors []FieldError
+	checkValue(value, valueType, "", &fieldErrors)
+	if len(fieldErrors) > 0 {
+		return &DecodeError{Fields: fieldErrors}
+	}
+	return nil
+}
+
+// checkValue appends to fieldErrors the discrepancies between the JSON value found at path and the given type.
+func checkValue(value json.RawMessage, valueType reflect.Type, path string, fieldErrors *[]FieldError) {
+	for valueType.Kind() == reflect.Pointer {
+		valueType = valueType.Elem()
+	}
+	jsonType := jsonTypeOf(value)
+	if jsonType == "null" {
+		return
+	}
+	if reflect.PointerTo(valueType).Implements(unmarshalerType) {
+		if err := json.Unmarshal(value, reflect.New(valueType).Interface()); err != nil {
+			*fieldErrors = append(*fieldErrors, FieldError{Path: path, Problem: err.Error()})
+		}
+		return
+	}
+
+	switch valueType.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(value, &fields) != nil {
+			break
+		}
+		knownFields := jsonFieldNames(valueType)
+		for _, name := range sortedKeys(fields) {
+			field, known := knownFields[strings.ToLower(name)]
+			if fieldName, _ := jsonName(field); !known || fieldName != name {
+				*fieldErrors = append(*fieldErrors, FieldError{Path: joinPath(path, name), Problem: "unknown field"})
+				continue
+			}
+			checkValue(fields[name], field.Type, joinPath(path, name), fieldErrors)
+		}
+		return
+	case reflect.Map:
+		var entries map[string]json.RawMessage
+		if json.Unmarshal(value, &entries) != nil {
+			break
+		}
+		for _, key := range sortedKeys(entries) {
+			checkValue(entries[key], valueType.Elem(), joinPath(path, key), fieldErrors)
+		}
+		return
+	case reflect.Slice:
+		var elements []json.RawMessage
+		if json.Unmarshal(value, &elements) != nil {
+			break
+		}
+		for i, element := range elements {
+			checkValue(element, valueType.Elem(), path+"["+strconv.Itoa(i)+"]", fieldErrors)
+		}
+		return
+	default:
+		if json.Unmarshal(value, reflect.New(valueType).Interface()) == nil {
+			return
+		}
+	}
+	*fieldErrors = append(*fieldErrors, FieldError{Path: path, Problem: "expected " + expectedJSONType(valueType) + " but got " + jsonType})
+}
+
+// jsonTypeOf returns the type of a valid JSON value.
+func jsonTypeOf(value json.RawMessage) string {
+	switch value[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// expectedJSONType returns the type of the JSON values that can be unmarshalled into the given type.
+func expectedJSONType(valueType reflect.Type) string {
+	switch valueType.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "integer"
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func sortedKeys(fields map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,76 @@
+package builder
+
+import (
+	"errors"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"strings"
+)
+
+// Violation describes a validation rule broken by a field of an account.
+type Violation struct {
+	// Path is the JSON path of the field, for example "data.attributes.bic".
+	Path string `json:"path"`
+	// Rule is the validation tag that failed, for example "required", "max" or "bic_country".
+	Rule string `json:"rule"`
+	// Param is the parameter of the rule, for example "140" for "max=140". It is empty for rules without parameter.
+	Param string `json:"param,omitempty"`
+	// Value is the rejected value.
+	Value interface{} `json:"value,omitempty"`
+	// Message is the human-readable description of the violation, in the locale given to BuildWithLocale.
+	Message string `json:"message,omitempty"`
+}
+
+func (v Violation) String() string {
+	if v.Param == "" {
+		return v.Path + ": " + v.Rule
+	}
+	return v.Path + ": " + v.Rule + "=" + v.Param
+}
+
+// ValidationError is returned by AccountBuilder.Build when the account is invalid, listing every violation.
+// It can be marshalled to JSON to be sent as is to end users.
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+
+	errs validator.ValidationErrors
+}
+
+func (e *ValidationError) Error() string {
+	violations := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		violations = append(violations, violation.String())
+	}
+	return "builder: invalid account: " + strings.Join(violations, "; ")
+}
+
+// Unwrap returns the validator.ValidationErrors the ValidationError was built from.
+func (e *ValidationError) Unwrap() error {
+	return e.errs
+}
+
+// newValidationError converts the validator.ValidationErrors inside err into a *ValidationError, whose messages
+// are translated by translator. Other errors are returned as is.
+func newValidationError(err error, translator ut.Translator) error {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	validationError := &ValidationError{Violations: make([]Violation, 0, len(errs)), errs: errs}
+	for _, fieldError := range errs {
+		validationError.Violations = append(validationError.Violations, Violation{
+			Path:    jsonPath(fieldError.Namespace()),
+			Rule:    fieldError.ActualTag(),
+			Param:   fieldError.Param(),
+			Value:   fieldError.Value(),
+			Message: fieldError.Translate(translator),
+		})
+	}
+	return validationError
+}
+
+// jsonPath removes the name of the validated struct from the namespace of a field, which is made of JSON names.
+func jsonPath(namespace string) string {
+	_, path, _ := strings.Cut(namespace, ".")
+	return path
+}
@@ -0,0 +1,85 @@
+package models
+
+import "encoding/json"
+
+// Copy returns a deep copy of the account: the copy shares no pointer, slice or map with the account,
+// so either of them can be modified without affecting the other.
+func (a *Account) Copy() *Account {
+	if a == nil {
+		return nil
+	}
+	return &Account{
+		Data:  a.Data.Copy(),
+		Links: copyPointer(a.Links),
+		Meta:  copyRawMessages(a.Meta),
+	}
+}
+
+// Copy returns a deep copy of the account data, see Account.Copy.
+func (d *AccountData) Copy() *AccountData {
+	if d == nil {
+		return nil
+	}
+	return &AccountData{
+		Attributes:     d.Attributes.Copy(),
+		CreatedOn:      copyPointer(d.CreatedOn),
+		ID:             d.ID,
+		ModifiedOn:     copyPointer(d.ModifiedOn),
+		OrganisationID: d.OrganisationID,
+		Type:           d.Type,
+		Version:        copyPointer(d.Version),
+		Extra:          copyRawMessages(d.Extra),
+	}
+}
+
+// Copy returns a deep copy of the account attributes, see Account.Copy.
+func (a *AccountAttributes) Copy() *AccountAttributes {
+	if a == nil {
+		return nil
+	}
+	return &AccountAttributes{
+		AccountClassification:   copyPointer(a.AccountClassification),
+		AccountNumber:           a.AccountNumber,
+		AlternativeNames:        copySlice(a.AlternativeNames),
+		BankID:                  a.BankID,
+		BankIDCode:              a.BankIDCode,
+		BaseCurrency:            a.BaseCurrency,
+		Bic:                     a.Bic,
+		Country:                 copyPointer(a.Country),
+		Iban:                    a.Iban,
+		JointAccount:            copyPointer(a.JointAccount),
+		Name:                    copySlice(a.Name),
+		NameMatchingStatus:      copyPointer(a.NameMatchingStatus),
+		SecondaryIdentification: a.SecondaryIdentification,
+		Status:                  copyPointer(a.Status),
+		Extra:                   copyRawMessages(a.Extra),
+	}
+}
+
+// copyPointer returns a pointer to a copy of the value of p, or nil if p is nil.
+func copyPointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	value := *p
+	return &value
+}
+
+// copySlice returns a copy of s, keeping nil slices nil.
+func copySlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
+
+func copyRawMessages(messages map[string]json.RawMessage) map[string]json.RawMessage {
+	if messages == nil {
+		return nil
+	}
+	copied := make(map[string]json.RawMessage, len(messages))
+	for name, message := range messages {
+		copied[name] = copySlice(message)
+	}
+	return copied
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fullAccount returns an account where every field is set, so copies missing a field are detected.
+func fullAccount() *Account {
+	classification, country, jointAccount, matching, status := BUSINESS, "GB", true, OPTED_OUT, CONFIRMED
+	createdOn, modifiedOn := time.Date(2022, 11, 2, 10, 0, 0, 0, time.UTC), time.Date(2022, 11, 3, 10, 0, 0, 0, time.UTC)
+	var version int64 = 2
+	return &Account{
+		Data: &AccountData{
+			Attributes: &AccountAttributes{
+				AccountClassification:   &classification,
+				AccountNumber:           "41920716",
+				AlternativeNames:        []string{"Bruce"},
+				BankID:                  "400300",
+				BankIDCode:              "GBDSC",
+				BaseCurrency:            "GBP",
+				Bic:                     "NWBKGB22",
+				Country:                 &country,
+				Iban:                    "GB33BUKB20201555555555",
+				JointAccount:            &jointAccount,
+				Name:                    []string{"Batman"},
+				NameMatchingStatus:      &matching,
+				SecondaryIdentification: "Alfred",
+				Status:                  &status,
+				Extra:                   map[string]json.RawMessage{"status_reason": json.RawMessage(`"unspecified"`)},
+			},
+			CreatedOn:      &createdOn,
+			ID:             "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+			ModifiedOn:     &modifiedOn,
+			OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+			Type:           ACCOUNTS,
+			Version:        &version,
+			Extra:          map[string]json.RawMessage{"relationships": json.RawMessage(`{}`)},
+		},
+		Links: &Links{Self: "/v1/organisation/accounts?page[number]=1", First: "/v1/organisation/accounts?page[number]=first",
+			Next: "/v1/organisation/accounts?page[number]=2", Prev: "/v1/organisation/accounts?page[number]=0",
+			Last: "/v1/organisation/accounts?page[number]=last"},
+		Meta: map[string]json.RawMessage{"count": json.RawMessage(`1`)},
+	}
+}
+
+func TestAccountCopy_CopiesEveryField(t *testing.T) {
+	account := fullAccount()
+	assertEveryFieldSet(t, reflect.ValueOf(account).Elem(), "Account")
+
+	assert.Equal(t, account, account.Copy())
+}
+
+func TestAccountCopy_SharesNoMemory(t *testing.T) {
+	account := fullAccount()
+	copied := account.Copy()
+	assertNoSharedMemory(t, reflect.ValueOf(account), reflect.ValueOf(copied), "Account")
+
+	copied.Data.Attributes.Name[0] = "Robin"
+	*copied.Data.Version = 3
+	copied.Data.Extra["relationships"][0] = '['
+	assert.Equal(t, fullAccount(), account)
+}
+
+func TestAccountCopy_KeepsNilFieldsNil(t *testing.T) {
+	var account *Account
+	assert.Nil(t, account.Copy())
+	assert.Equal(t, &Account{Data: &AccountData{}}, (&Account{Data: &AccountData{}}).Copy())
+}
+
+// assertEveryFieldSet asserts that every field of the struct value, and of the structs it points to, is not zero.
+func assertEveryFieldSet(t *testing.T, value reflect.Value, path string) {
+	for i := 0; i < value.NumField(); i++ {
+		field, fieldPath := value.Field(i), path+"."+value.Type().Field(i).Name
+		if !assert.False(t, field.IsZero(), fieldPath) {
+			continue
+		}
+		if field.Kind() == reflect.Pointer && field.Elem().Kind() == reflect.Struct && field.Elem().Type().PkgPath() == value.Type().PkgPath() {
+			assertEveryFieldSet(t, field.Elem(), fieldPath)
+		}
+	}
+}
+
+// assertNoSharedMemory asserts that the pointers, slices and maps found in original and copied are all different.
+func assertNoSharedMemory(t *testing.T, original, copied reflect.Value, path string) {
+	switch original.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		if original.IsNil() {
+			return
+		}
+		assert.NotEqual(t, original.Pointer(), copied.Pointer(), path)
+	}
+	switch original.Kind() {
+	case reflect.Pointer:
+		assertNoSharedMemory(t, original.Elem(), copied.Elem(), path)
+	case reflect.Struct:
+		for i := 0; i < original.NumField(); i++ {
+			assertNoSharedMemory(t, original.Field(i), copied.Field(i), path+"."+original.Type().Field(i).Name)
+		}
+	case reflect.Slice:
+		for i := 0; i < original.Len(); i++ {
+			assertNoSharedMemory(t, original.Index(i), copied.Index(i), path)
+		}
+	case reflect.Map:
+		for _, key := range original.MapKeys() {
+			assertNoSharedMemory(t, original.MapIndex(key), copied.MapIndex(key), path+"."+key.String())
+		}
+	}
+}
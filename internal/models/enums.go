@@ -0,0 +1,77 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownValue is wrapped by the errors returned when parsing or unmarshalling a value that is not one of the
+// constants of an enum type, like AccountStatus.
+var ErrUnknownValue = errors.New("models: unknown value")
+
+var accountStatuses = []AccountStatus{PENDING, FAILED, CONFIRMED, CLOSED}
+
+var accountClassifications = []AccountClassification{PERSONAL, BUSINESS}
+
+var nameMatchingStatuses = []NameMatchingStatus{SUPPORTED, NOT_SUPPORTED, OPTED_OUT, SWITCHED}
+
+// ParseAccountStatus returns the AccountStatus with the given value, as sent by the API.
+// Values are case-sensitive and unknown ones return an error wrapping ErrUnknownValue.
+func ParseAccountStatus(value string) (AccountStatus, error) {
+	return parseEnum(value, accountStatuses, "account status")
+}
+
+// ParseAccountClassification returns the AccountClassification with the given value, as sent by the API.
+// Values are case-sensitive and unknown ones return an error wrapping ErrUnknownValue.
+func ParseAccountClassification(value string) (AccountClassification, error) {
+	return parseEnum(value, accountClassifications, "account classification")
+}
+
+// ParseNameMatchingStatus returns the NameMatchingStatus with the given value, as sent by the API.
+// Values are case-sensitive and unknown ones return an error wrapping ErrUnknownValue.
+func ParseNameMatchingStatus(value string) (NameMatchingStatus, error) {
+	return parseEnum(value, nameMatchingStatuses, "name matching status")
+}
+
+// UnmarshalJSON unmarshals an account status, failing for unknown values, see ParseAccountStatus.
+func (s *AccountStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, s, ParseAccountStatus)
+}
+
+// UnmarshalJSON unmarshals an account classification, failing for unknown values, see ParseAccountClassification.
+func (c *AccountClassification) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, c, ParseAccountClassification)
+}
+
+// UnmarshalJSON unmarshals a name matching status, failing for unknown values, see ParseNameMatchingStatus.
+func (s *NameMatchingStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, s, ParseNameMatchingStatus)
+}
+
+// parseEnum returns the constant of values equal to value, where name describes the enum in errors.
+func parseEnum[E ~string](value string, values []E, name string) (E, error) {
+	for _, enumValue := range values {
+		if string(enumValue) == value {
+			return enumValue, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s %q, expected one of %q", ErrUnknownValue, name, value, values)
+}
+
+// unmarshalEnum unmarshals the JSON string data into enum using parse. JSON null leaves enum unchanged.
+func unmarshalEnum[E ~string](data []byte, enum *E, parse func(string) (E, error)) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := parse(value)
+	if err != nil {
+		return err
+	}
+	*enum = parsed
+	return nil
+}
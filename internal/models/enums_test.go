@@ -0,0 +1,74 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseAccountStatus_ReturnsKnownStatuses(t *testing.T) {
+	for _, value := range []string{"pending", "failed", "confirmed", "closed"} {
+		status, err := ParseAccountStatus(value)
+		if assert.Nil(t, err, value) {
+			assert.Equal(t, AccountStatus(value), status)
+		}
+	}
+	assert.Equal(t, AccountStatus("failed"), FAILED)
+}
+
+func TestParseEnums_UnknownValuesReturnErrUnknownValue(t *testing.T) {
+	_, err := ParseAccountStatus("faied")
+	assert.ErrorIs(t, err, ErrUnknownValue)
+	_, err = ParseAccountStatus("Pending")
+	assert.ErrorIs(t, err, ErrUnknownValue)
+	_, err = ParseAccountClassification("personal")
+	assert.ErrorIs(t, err, ErrUnknownValue)
+	_, err = ParseNameMatchingStatus("")
+	assert.ErrorIs(t, err, ErrUnknownValue)
+
+	_, err = ParseAccountStatus("faied")
+	assert.EqualError(t, err, `models: unknown value: account status "faied", expected one of ["pending" "failed" "confirmed" "closed"]`)
+}
+
+func TestParseEnums_ReturnKnownValues(t *testing.T) {
+	classification, err := ParseAccountClassification("Business")
+	if assert.Nil(t, err) {
+		assert.Equal(t, BUSINESS, classification)
+	}
+	matching, err := ParseNameMatchingStatus("opted_out")
+	if assert.Nil(t, err) {
+		assert.Equal(t, OPTED_OUT, matching)
+	}
+}
+
+func TestUnmarshalJSON_ValidatesEnumValues(t *testing.T) {
+	var attributes AccountAttributes
+	err := json.Unmarshal([]byte(`{"account_classification":"Business","name_matching_status":"switched","status":"failed"}`), &attributes)
+	if assert.Nil(t, err) {
+		assert.Equal(t, BUSINESS, *attributes.AccountClassification)
+		assert.Equal(t, SWITCHED, *attributes.NameMatchingStatus)
+		assert.Equal(t, FAILED, *attributes.Status)
+	}
+
+	for _, attributesJSON := range []string{
+		`{"status":"faied"}`,
+		`{"account_classification":"Corporate"}`,
+		`{"name_matching_status":"SUPPORTED"}`,
+	} {
+		err := json.Unmarshal([]byte(attributesJSON), &AccountAttributes{})
+		assert.ErrorIs(t, err, ErrUnknownValue, attributesJSON)
+	}
+	assert.NotNil(t, json.Unmarshal([]byte(`{"status":1}`), &AccountAttributes{}))
+}
+
+func TestUnmarshalJSON_NullLeavesEnumUnset(t *testing.T) {
+	var attributes AccountAttributes
+	err := json.Unmarshal([]byte(`{"status":null}`), &attributes)
+	if assert.Nil(t, err) {
+		assert.Nil(t, attributes.Status)
+	}
+	status := PENDING
+	if assert.Nil(t, status.UnmarshalJSON([]byte("null"))) {
+		assert.Equal(t, PENDING, status)
+	}
+}
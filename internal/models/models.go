@@ -3,11 +3,17 @@
 // more information about fields.
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
+// AccountStatus is the status of an account. Accounts can only change of status as allowed by AllowedTransition.
 type AccountStatus string
 
 const (
 	PENDING   AccountStatus = "pending"
-	FAILED    AccountStatus = "faied"
+	FAILED    AccountStatus = "failed"
 	CONFIRMED AccountStatus = "confirmed"
 	CLOSED    AccountStatus = "closed"
 )
@@ -34,31 +40,89 @@ const (
 	SWITCHED      NameMatchingStatus = "switched"
 )
 
+// Account is the JSON:API envelope of a single account: its data alongside the links and meta returned by the API.
 type Account struct {
-	Data *AccountData `json:"data,omitempty"`
+	Data  *AccountData               `json:"data,omitempty"`
+	Links *Links                     `json:"links,omitempty"`
+	Meta  map[string]json.RawMessage `json:"meta,omitempty"`
 }
 
 type AccountData struct {
 	Attributes     *AccountAttributes `json:"attributes,omitempty"`
+	CreatedOn      *time.Time         `json:"created_on,omitempty"`
 	ID             string             `json:"id,omitempty" validate:"required,uuid"`
+	ModifiedOn     *time.Time         `json:"modified_on,omitempty"`
 	OrganisationID string             `json:"organisation_id,omitempty" validate:"required,uuid"`
 	Type           AccountType        `json:"type,omitempty" validate:"required"`
 	Version        *int64             `json:"version,omitempty" validate:"min=0"`
+	// Extra holds the fields of the account data unknown to this model, kept when decoding in lossless mode
+	// and sent back when the account is marshalled.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON marshals the account data alongside its Extra fields.
+func (d AccountData) MarshalJSON() ([]byte, error) {
+	type accountData AccountData
+	return marshalWithExtra(accountData(d), d.Extra)
 }
 
 type AccountAttributes struct {
 	AccountClassification   *AccountClassification `json:"account_classification,omitempty"`
 	AccountNumber           string                 `json:"account_number,omitempty" validate:"omitempty,alphanum,max=64"`
 	AlternativeNames        []string               `json:"alternative_names,omitempty" validate:"max=3,dive,min=1,max=140"`
-	BankID                  string                 `json:"bank_id,omitempty" validate:"required,max=11,alphanum"`
-	BankIDCode              string                 `json:"bank_id_code,omitempty" validate:"required,alphanum,max=16"`
+	BankID                  string                 `json:"bank_id,omitempty" validate:"omitempty,max=11,alphanum"`
+	BankIDCode              string                 `json:"bank_id_code,omitempty" validate:"omitempty,alphanum,max=16"`
 	BaseCurrency            string                 `json:"base_currency,omitempty" validate:"iso4217"`
-	Bic                     string                 `json:"bic,omitempty" validate:"required,alphanum,len=8|len=11"`
+	Bic                     string                 `json:"bic,omitempty" validate:"omitempty,bic"`
 	Country                 *string                `json:"country,omitempty" validate:"required,iso3166_1_alpha2,len=2"`
-	Iban                    string                 `json:"iban,omitempty"`
+	Iban                    string                 `json:"iban,omitempty" validate:"omitempty,iban"`
 	JointAccount            *bool                  `json:"joint_account,omitempty"`
 	Name                    []string               `json:"name,omitempty" validate:"required,max=4,dive,min=1,max=140"`
 	NameMatchingStatus      *NameMatchingStatus    `json:"name_matching_status,omitempty"` // Changed from AccountMatchingOptOut+Switched since it's replaced in the docs (deprecation).
 	SecondaryIdentification string                 `json:"secondary_identification,omitempty" validate:"max=140"`
 	Status                  *AccountStatus         `json:"status,omitempty"`
+	// Extra holds the attributes unknown to this model, kept when decoding in lossless mode
+	// and sent back when the account is marshalled.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON marshals the account attributes alongside their Extra fields.
+func (a AccountAttributes) MarshalJSON() ([]byte, error) {
+	type accountAttributes AccountAttributes
+	return marshalWithExtra(accountAttributes(a), a.Extra)
+}
+
+// marshalWithExtra marshals v, which must marshal to a JSON object, and adds the extra fields to it.
+// Fields known to v take precedence over extra fields with the same name.
+func marshalWithExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	marshalled, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return marshalled, err
+	}
+	fields := make(map[string]json.RawMessage, len(extra))
+	if err := json.Unmarshal(marshalled, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range extra {
+		if _, known := fields[name]; !known {
+			fields[name] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// Links contains the JSON:API links returned by the API, used to navigate between resources and pages.
+type Links struct {
+	Self  string `json:"self,omitempty"`
+	First string `json:"first,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// AccountList represents a page of accounts as returned by the list endpoint.
+type AccountList struct {
+	Data  []*AccountData             `json:"data"`
+	Links *Links                     `json:"links,omitempty"`
+	Meta  map[string]json.RawMessage `json:"meta,omitempty"`
 }
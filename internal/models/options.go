@@ -0,0 +1,148 @@
+package models
+
+// The identifiers of an account have their own types, so passing one where another is expected, like a BIC
+// as a bank ID, is a compile error. Untyped string constants convert to them implicitly: WithBankID("400300").
+type (
+	// AccountID is the ID of an account, a UUID chosen by the client.
+	AccountID string
+	// OrganisationID is the ID of the organisation owning an account, a UUID.
+	OrganisationID string
+	// BankID is the local identifier of the bank, like a UK sort code.
+	BankID string
+	// BankIDCode identifies the type of BankID, like GBDSC for UK sort codes.
+	BankIDCode string
+	// BIC is the SWIFT BIC of the bank, 8 or 11 characters long.
+	BIC string
+	// CountryCode is an ISO 3166-1 alpha-2 country code, like GB.
+	CountryCode string
+)
+
+// AccountOption sets a field of the account created by NewAccount.
+type AccountOption func(*Account)
+
+// NewAccount returns an account with the given options applied, in order, on top of the defaults of the Form3 docs
+// https://www.api-docs.form3.tech/api/schemes/fps-direct/accounts/accounts/create-an-account:
+// a personal account, not joint, with GBP as base currency, supported name matching and version 0.
+// The account is not validated, build it with builder.FromOptions to validate it.
+//
+//	account := models.NewAccount(
+//		models.WithID(id),
+//		models.WithOrganisationID(organisationID),
+//		models.WithBankID("400300"),
+//		models.WithBankIDCode("GBDSC"),
+//		models.WithBIC("NWBKGB22"),
+//		models.WithCountry("GB"),
+//		models.WithName("Bruce Wayne"),
+//	)
+func NewAccount(opts ...AccountOption) *Account {
+	account := &Account{Data: &AccountData{
+		Attributes: &AccountAttributes{},
+		Type:       ACCOUNTS,
+	}}
+	defaults := []AccountOption{
+		WithAccountClassification(PERSONAL),
+		WithJointAccount(false),
+		WithVersion(0),
+		WithNameMatchingStatus(SUPPORTED),
+		WithBaseCurrency("GBP"),
+	}
+	for _, option := range append(defaults, opts...) {
+		option(account)
+	}
+	return account
+}
+
+// The options never share memory between the accounts they are applied to, and copy the slices given to them,
+// so accounts are not affected by later changes to them.
+// They can also be applied to accounts that were not created by NewAccount.
+
+func WithID(ID AccountID) AccountOption {
+	return func(a *Account) { data(a).ID = string(ID) }
+}
+
+func WithOrganisationID(organisationID OrganisationID) AccountOption {
+	return func(a *Account) { data(a).OrganisationID = string(organisationID) }
+}
+
+func WithVersion(version int64) AccountOption {
+	return func(a *Account) { data(a).Version = copyPointer(&version) }
+}
+
+func WithBankID(bankID BankID) AccountOption {
+	return func(a *Account) { attributes(a).BankID = string(bankID) }
+}
+
+func WithBankIDCode(bankIDCode BankIDCode) AccountOption {
+	return func(a *Account) { attributes(a).BankIDCode = string(bankIDCode) }
+}
+
+func WithBIC(bic BIC) AccountOption {
+	return func(a *Account) { attributes(a).Bic = string(bic) }
+}
+
+func WithCountry(country CountryCode) AccountOption {
+	return func(a *Account) {
+		code := string(country)
+		attributes(a).Country = &code
+	}
+}
+
+// WithName sets the names of the account holder, up to 4.
+func WithName(names ...string) AccountOption {
+	names = copySlice(names)
+	return func(a *Account) { attributes(a).Name = copySlice(names) }
+}
+
+// WithAlternativeNames sets the alternative names of the account holder, up to 3.
+func WithAlternativeNames(alternativeNames ...string) AccountOption {
+	alternativeNames = copySlice(alternativeNames)
+	return func(a *Account) { attributes(a).AlternativeNames = copySlice(alternativeNames) }
+}
+
+func WithAccountClassification(classification AccountClassification) AccountOption {
+	return func(a *Account) { attributes(a).AccountClassification = copyPointer(&classification) }
+}
+
+func WithNameMatchingStatus(matching NameMatchingStatus) AccountOption {
+	return func(a *Account) { attributes(a).NameMatchingStatus = copyPointer(&matching) }
+}
+
+func WithAccountNumber(number string) AccountOption {
+	return func(a *Account) { attributes(a).AccountNumber = number }
+}
+
+func WithBaseCurrency(baseCurrency string) AccountOption {
+	return func(a *Account) { attributes(a).BaseCurrency = baseCurrency }
+}
+
+func WithIban(iban string) AccountOption {
+	return func(a *Account) { attributes(a).Iban = iban }
+}
+
+func WithJointAccount(jointAccount bool) AccountOption {
+	return func(a *Account) { attributes(a).JointAccount = copyPointer(&jointAccount) }
+}
+
+func WithSecondaryIdentification(secondaryIdentification string) AccountOption {
+	return func(a *Account) { attributes(a).SecondaryIdentification = secondaryIdentification }
+}
+
+func WithStatus(status AccountStatus) AccountOption {
+	return func(a *Account) { attributes(a).Status = copyPointer(&status) }
+}
+
+// data returns the data of the account, creating it if needed.
+func data(account *Account) *AccountData {
+	if account.Data == nil {
+		account.Data = &AccountData{Type: ACCOUNTS}
+	}
+	return account.Data
+}
+
+// attributes returns the attributes of the account, creating them if needed.
+func attributes(account *Account) *AccountAttributes {
+	if data(account).Attributes == nil {
+		account.Data.Attributes = &AccountAttributes{}
+	}
+	return account.Data.Attributes
+}
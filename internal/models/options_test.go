@@ -0,0 +1,74 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewAccount_SetsDefaultsAndOptions(t *testing.T) {
+	account := NewAccount(
+		WithID("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"),
+		WithOrganisationID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"),
+		WithBankID("400300"),
+		WithBankIDCode("GBDSC"),
+		WithBIC("NWBKGB22"),
+		WithCountry("GB"),
+		WithName("Bruce", "Wayne"),
+		WithStatus(PENDING),
+	)
+
+	classification, jointAccount, matching, country, status := PERSONAL, false, SUPPORTED, "GB", PENDING
+	var version int64
+	assert.Equal(t, &Account{Data: &AccountData{
+		Attributes: &AccountAttributes{
+			AccountClassification: &classification,
+			BankID:                "400300",
+			BankIDCode:            "GBDSC",
+			BaseCurrency:          "GBP",
+			Bic:                   "NWBKGB22",
+			Country:               &country,
+			JointAccount:          &jointAccount,
+			Name:                  []string{"Bruce", "Wayne"},
+			NameMatchingStatus:    &matching,
+			Status:                &status,
+		},
+		ID:             "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+		OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+		Type:           ACCOUNTS,
+		Version:        &version,
+	}}, account)
+}
+
+func TestNewAccount_OptionsOverrideDefaults(t *testing.T) {
+	account := NewAccount(WithBaseCurrency("EUR"), WithAccountClassification(BUSINESS), WithVersion(2))
+
+	assert.Equal(t, "EUR", account.Data.Attributes.BaseCurrency)
+	assert.Equal(t, BUSINESS, *account.Data.Attributes.AccountClassification)
+	assert.Equal(t, int64(2), *account.Data.Version)
+}
+
+func TestAccountOption_SharesNoMemoryBetweenAccounts(t *testing.T) {
+	names := []string{"Batman"}
+	withName, withVersion, withCountry := WithName(names...), WithVersion(1), WithCountry("GB")
+	account, otherAccount := NewAccount(withName, withVersion, withCountry), NewAccount(withName, withVersion, withCountry)
+
+	names[0] = "Joker"
+	account.Data.Attributes.Name[0] = "Robin"
+	*account.Data.Version = 5
+	*account.Data.Attributes.Country = "FR"
+
+	assert.Equal(t, []string{"Batman"}, otherAccount.Data.Attributes.Name)
+	assert.Equal(t, int64(1), *otherAccount.Data.Version)
+	assert.Equal(t, "GB", *otherAccount.Data.Attributes.Country)
+	assert.Equal(t, []string{"Batman"}, NewAccount(withName).Data.Attributes.Name)
+}
+
+func TestAccountOption_CreatesMissingDataAndAttributes(t *testing.T) {
+	account := &Account{}
+	WithIban("GB33BUKB20201555555555")(account)
+
+	if assert.NotNil(t, account.Data) && assert.NotNil(t, account.Data.Attributes) {
+		assert.Equal(t, ACCOUNTS, account.Data.Type)
+		assert.Equal(t, "GB33BUKB20201555555555", account.Data.Attributes.Iban)
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+// statusTransitions is the state machine of account statuses: the statuses an account can change to from each status.
+// Accounts start as pending and are either confirmed or failed by the scheme. Confirmed accounts can then be closed.
+// Failed and closed accounts can no longer change of status.
+//
+//	pending ──> confirmed ──> closed
+//	   │
+//	   └──────> failed
+var statusTransitions = map[AccountStatus][]AccountStatus{
+	PENDING:   {CONFIRMED, FAILED},
+	CONFIRMED: {CLOSED},
+}
+
+// AllowedTransition tells whether an account with status from can be updated to status to, according to the state
+// machine of account statuses: pending accounts can be confirmed or failed and confirmed accounts can be closed.
+// Keeping the same status is always allowed, since it does not change the account.
+func AllowedTransition(from, to AccountStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"slices"
+	"testing"
+)
+
+func TestAllowedTransition_FollowsStateMachine(t *testing.T) {
+	allowed := map[AccountStatus][]AccountStatus{
+		PENDING:   {PENDING, CONFIRMED, FAILED},
+		CONFIRMED: {CONFIRMED, CLOSED},
+		FAILED:    {FAILED},
+		CLOSED:    {CLOSED},
+	}
+	for _, from := range accountStatuses {
+		for _, to := range accountStatuses {
+			assert.Equal(t, slices.Contains(allowed[from], to), AllowedTransition(from, to), "%s -> %s", from, to)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package validation
+
+import (
+	"errors"
+	"github.com/go-playground/validator/v10"
+)
+
+// Errors returned by ParseBIC, describing the first part of the BIC that is invalid.
+var (
+	ErrBICLength      = errors.New("validation: BIC must be 8 or 11 characters long")
+	ErrBICInstitution = errors.New("validation: BIC institution code must be 4 letters")
+	ErrBICCountry     = errors.New("validation: BIC country code must be an ISO 3166-1 alpha-2 country code")
+	ErrBICLocation    = errors.New("validation: BIC location code must be 2 letters or digits")
+	ErrBICBranch      = errors.New("validation: BIC branch code must be 3 letters or digits")
+)
+
+// BIC contains the components of a BIC (ISO 9362), also known as SWIFT code.
+type BIC struct {
+	// Institution is the 4 letters code of the bank, for example "NWBK".
+	Institution string
+	// Country is the ISO 3166-1 alpha-2 code of the country of the bank, for example "GB".
+	Country string
+	// Location is the 2 characters code of the location of the bank, for example "22".
+	Location string
+	// Branch is the 3 characters code of the branch, empty for 8 characters BICs. "XXX" designates the primary office.
+	Branch string
+}
+
+// String returns the BIC as a single code.
+func (b BIC) String() string {
+	return b.Institution + b.Country + b.Location + b.Branch
+}
+
+// PrimaryOffice reports whether the BIC designates the primary office of the bank rather than a branch.
+func (b BIC) PrimaryOffice() bool {
+	return b.Branch == "" || b.Branch == "XXX"
+}
+
+// bicCheck is the check of one part of a BIC, registered as a validation under its tag.
+type bicCheck struct {
+	tag   string
+	err   error
+	valid func(bic string) bool
+}
+
+// bicChecks are run in order: each check assumes the previous ones passed.
+var bicChecks = []bicCheck{
+	{"bic_length", ErrBICLength, func(bic string) bool {
+		return len(bic) == 8 || len(bic) == 11
+	}},
+	{"bic_institution", ErrBICInstitution, func(bic string) bool {
+		return len(bic) >= 4 && isUpperLetters(bic[0:4])
+	}},
+	{"bic_country", ErrBICCountry, func(bic string) bool {
+		return len(bic) >= 6 && isUpperLetters(bic[4:6]) && countries.Var(bic[4:6], "iso3166_1_alpha2") == nil
+	}},
+	{"bic_location", ErrBICLocation, func(bic string) bool {
+		return len(bic) >= 8 && isUpperAlphanumeric(bic[6:8])
+	}},
+	{"bic_branch", ErrBICBranch, func(bic string) bool {
+		return len(bic) == 8 || (len(bic) == 11 && isUpperAlphanumeric(bic[8:11]))
+	}},
+}
+
+// countries validates ISO 3166-1 country codes with the list of go-playground/validator.
+var countries = validator.New()
+
+// bicCountryAliases lists the BIC countries accepted for accounts of territories whose banks use the BICs
+// of another country, like Jersey with British BICs.
+var bicCountryAliases = map[string][]string{
+	"JE": {"GB"},
+	"GG": {"GB"},
+	"IM": {"GB"},
+	"GF": {"FR"},
+	"GP": {"FR"},
+	"MQ": {"FR"},
+	"RE": {"FR"},
+	"YT": {"FR"},
+	"PM": {"FR"},
+	"BL": {"FR"},
+	"MF": {"FR"},
+}
+
+// ParseBIC splits a BIC into its components. The error is one of the ErrBIC errors, describing the first invalid part.
+func ParseBIC(bic string) (BIC, error) {
+	for _, check := range bicChecks {
+		if !check.valid(bic) {
+			return BIC{}, check.err
+		}
+	}
+	parsed := BIC{Institution: bic[0:4], Country: bic[4:6], Location: bic[6:8]}
+	if len(bic) == 11 {
+		parsed.Branch = bic[8:11]
+	}
+	return parsed, nil
+}
+
+// ValidateBIC checks that bic is a valid BIC. The error is one of the ErrBIC errors.
+func ValidateBIC(bic string) error {
+	_, err := ParseBIC(bic)
+	return err
+}
+
+// BICMatchesCountry reports whether the country of a valid BIC agrees with the country of the account.
+func BICMatchesCountry(bic BIC, country string) bool {
+	if bic.Country == country {
+		return true
+	}
+	for _, alias := range bicCountryAliases[country] {
+		if bic.Country == alias {
+			return true
+		}
+	}
+	return false
+}
+
+func isUpperLetters(s string) bool {
+	for _, char := range s {
+		if char < 'A' || char > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isUpperAlphanumeric(s string) bool {
+	for _, char := range s {
+		if (char < 'A' || char > 'Z') && (char < '0' || char > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseBIC_ReturnsComponents(t *testing.T) {
+	bic, err := ParseBIC("NWBKGB22")
+	if assert.Nil(t, err) {
+		assert.Equal(t, BIC{Institution: "NWBK", Country: "GB", Location: "22"}, bic)
+		assert.True(t, bic.PrimaryOffice())
+		assert.Equal(t, "NWBKGB22", bic.String())
+	}
+
+	bic, err = ParseBIC("DEUTDEFF500")
+	if assert.Nil(t, err) {
+		assert.Equal(t, BIC{Institution: "DEUT", Country: "DE", Location: "FF", Branch: "500"}, bic)
+		assert.False(t, bic.PrimaryOffice())
+		assert.Equal(t, "DEUTDEFF500", bic.String())
+	}
+}
+
+func TestParseBIC_InvalidBICsReturnInvalidPart(t *testing.T) {
+	tests := map[string]error{
+		"":            ErrBICLength,
+		"NWBKGB2":     ErrBICLength,
+		"NWBKGB2200":  ErrBICLength,
+		"NWB1GB22":    ErrBICInstitution,
+		"nwbkGB22":    ErrBICInstitution,
+		"NWBKZZ22":    ErrBICCountry,
+		"NWBKgb22":    ErrBICCountry,
+		"NWBKGB2$":    ErrBICLocation,
+		"DEUTDEFF5-0": ErrBICBranch,
+	}
+	for bic, expectedErr := range tests {
+		_, err := ParseBIC(bic)
+		assert.ErrorIs(t, err, expectedErr, bic)
+	}
+}
+
+func TestBICMatchesCountry_AcceptsTerritoriesUsingForeignBICs(t *testing.T) {
+	bic, _ := ParseBIC("NWBKGB22")
+	assert.True(t, BICMatchesCountry(bic, "GB"))
+	assert.True(t, BICMatchesCountry(bic, "JE"))
+	assert.False(t, BICMatchesCountry(bic, "FR"))
+}
+
+func TestNew_BICValidationErrorTellsInvalidPart(t *testing.T) {
+	validate := New()
+	assert.Nil(t, validate.Var("NWBKGB22", "bic"))
+
+	err := validate.Var("NWBKZZ22", "bic")
+	var validationErrors validator.ValidationErrors
+	if assert.ErrorAs(t, err, &validationErrors) {
+		assert.Equal(t, "bic", validationErrors[0].Tag())
+		assert.Equal(t, "bic_country", validationErrors[0].ActualTag())
+	}
+}
+
+func TestNew_BICMustMatchAccountCountry(t *testing.T) {
+	validate := New()
+	country := "FR"
+	attributes := models.AccountAttributes{BankID: "2004101005", BankIDCode: "FR", BaseCurrency: "EUR", Bic: "NWBKGB22", Country: &country, Name: []string{"Batman"}}
+
+	err := validate.Struct(attributes)
+	var validationErrors validator.ValidationErrors
+	if assert.ErrorAs(t, err, &validationErrors) && assert.Len(t, validationErrors, 1) {
+		assert.Equal(t, "bic_matches_country", validationErrors[0].Tag())
+		assert.Equal(t, "FR", validationErrors[0].Param())
+	}
+
+	country = "JE"
+	assert.Nil(t, validate.Struct(attributes))
+}
@@ -0,0 +1,238 @@
+package validation
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Presence tells whether a field must, may or must not be set.
+type Presence int
+
+const (
+	Optional Presence = iota
+	Required
+	Forbidden
+)
+
+// FieldRule describes the values accepted for a field of an account in a country.
+// Zero values mean no restriction.
+type FieldRule struct {
+	Presence Presence
+	// MinLength and MaxLength bound the length of the field when it is set.
+	MinLength, MaxLength int
+	// Numeric restricts the field to digits.
+	Numeric bool
+	// Values lists the only values accepted for the field.
+	Values []string
+	// StartsWith and StartsNotWith are a prefix the field must or must not start with.
+	StartsWith, StartsNotWith string
+}
+
+// CountryRules are the rules applied to the fields of an account depending on its country.
+type CountryRules struct {
+	BankID FieldRule
+	// BankIDWithoutAccountNumber replaces BankID for the accounts without an account number, when it is set.
+	BankIDWithoutAccountNumber *FieldRule
+	BankIDCode                 FieldRule
+	Bic                        FieldRule
+	AccountNumber              FieldRule
+	Iban                       FieldRule
+}
+
+// DefaultCountryRules apply to the countries without specific rules.
+var DefaultCountryRules = CountryRules{
+	BankID:     FieldRule{Presence: Required},
+	BankIDCode: FieldRule{Presence: Required},
+	Bic:        FieldRule{Presence: Required},
+}
+
+// countryRules are the rules documented by Form3 for each supported country, see
+// https://www.api-docs.form3.tech/api/schemes/fps-direct/accounts/accounts/create-an-account
+var countryRules = map[string]CountryRules{
+	// United Kingdom: sort code.
+	"GB": {
+		BankID:        FieldRule{Presence: Required, MinLength: 6, MaxLength: 6, Numeric: true},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"GBDSC"}},
+		Bic:           FieldRule{Presence: Required},
+		AccountNumber: FieldRule{MinLength: 8, MaxLength: 8, Numeric: true},
+	},
+	// Australia: BSB code. Account numbers cannot start with 0.
+	"AU": {
+		BankID:        FieldRule{MinLength: 6, MaxLength: 6, Numeric: true},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"AUBSB"}},
+		Bic:           FieldRule{Presence: Required},
+		AccountNumber: FieldRule{MinLength: 6, MaxLength: 10, Numeric: true, StartsNotWith: "0"},
+		Iban:          FieldRule{Presence: Forbidden},
+	},
+	// Belgium.
+	"BE": {
+		BankID:        FieldRule{Presence: Required, MinLength: 3, MaxLength: 3, Numeric: true},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"BE"}},
+		AccountNumber: FieldRule{MinLength: 7, MaxLength: 7, Numeric: true},
+	},
+	// Canada: routing number, a leading 0 followed by the institution and branch numbers.
+	"CA": {
+		BankID:        FieldRule{MinLength: 9, MaxLength: 9, Numeric: true, StartsWith: "0"},
+		BankIDCode:    FieldRule{Values: []string{"CACPA"}},
+		Bic:           FieldRule{Presence: Required},
+		AccountNumber: FieldRule{MinLength: 7, MaxLength: 12, Numeric: true},
+		Iban:          FieldRule{Presence: Forbidden},
+	},
+	// France: bank and branch codes.
+	"FR": {
+		BankID:        FieldRule{Presence: Required, MinLength: 10, MaxLength: 10},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"FR"}},
+		AccountNumber: FieldRule{MinLength: 10, MaxLength: 10},
+	},
+	// Germany: Bankleitzahl (BLZ).
+	"DE": {
+		BankID:        FieldRule{Presence: Required, MinLength: 8, MaxLength: 8, Numeric: true},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"DEBLZ"}},
+		AccountNumber: FieldRule{MinLength: 7, MaxLength: 7, Numeric: true},
+	},
+	// Greece: HEBIC.
+	"GR": {
+		BankID:        FieldRule{Presence: Required, MinLength: 7, MaxLength: 7, Numeric: true},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"GRBIC"}},
+		AccountNumber: FieldRule{MinLength: 16, MaxLength: 16},
+	},
+	// Hong Kong: bank code.
+	"HK": {
+		BankID:        FieldRule{MinLength: 3, MaxLength: 3, Numeric: true},
+		BankIDCode:    FieldRule{Values: []string{"HKNCC"}},
+		Bic:           FieldRule{Presence: Required},
+		AccountNumber: FieldRule{MinLength: 9, MaxLength: 12, Numeric: true},
+		Iban:          FieldRule{Presence: Forbidden},
+	},
+	// Italy: ABI and CAB codes, with the CIN check character when the account number is not set.
+	"IT": {
+		BankID:                     FieldRule{Presence: Required, MinLength: 10, MaxLength: 10},
+		BankIDWithoutAccountNumber: &FieldRule{Presence: Required, MinLength: 11, MaxLength: 11},
+		BankIDCode:                 FieldRule{Presence: Required, Values: []string{"ITNCC"}},
+		AccountNumber:              FieldRule{MinLength: 12, MaxLength: 12},
+	},
+	// Luxembourg.
+	"LU": {
+		BankID:        FieldRule{Presence: Required, MinLength: 3, MaxLength: 3, Numeric: true},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"LULUX"}},
+		AccountNumber: FieldRule{MinLength: 13, MaxLength: 13},
+	},
+	// Netherlands: banks are only identified by their BIC.
+	"NL": {
+		BankID:        FieldRule{Presence: Forbidden},
+		BankIDCode:    FieldRule{Presence: Forbidden},
+		Bic:           FieldRule{Presence: Required},
+		AccountNumber: FieldRule{MinLength: 10, MaxLength: 10, Numeric: true},
+	},
+	// Poland: KNR.
+	"PL": {
+		BankID:        FieldRule{Presence: Required, MinLength: 8, MaxLength: 8, Numeric: true},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"PLKNR"}},
+		AccountNumber: FieldRule{MinLength: 16, MaxLength: 16, Numeric: true},
+	},
+	// Portugal.
+	"PT": {
+		BankID:        FieldRule{Presence: Required, MinLength: 8, MaxLength: 8, Numeric: true},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"PTNCC"}},
+		AccountNumber: FieldRule{MinLength: 11, MaxLength: 11, Numeric: true},
+	},
+	// Spain.
+	"ES": {
+		BankID:        FieldRule{Presence: Required, MinLength: 8, MaxLength: 8, Numeric: true},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"ESNCC"}},
+		AccountNumber: FieldRule{MinLength: 10, MaxLength: 10, Numeric: true},
+	},
+	// Switzerland: bank clearing code.
+	"CH": {
+		BankID:        FieldRule{Presence: Required, MinLength: 5, MaxLength: 5, Numeric: true},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"CHBCC"}},
+		AccountNumber: FieldRule{MinLength: 12, MaxLength: 12},
+	},
+	// United States: ABA routing number.
+	"US": {
+		BankID:        FieldRule{Presence: Required, MinLength: 9, MaxLength: 9, Numeric: true},
+		BankIDCode:    FieldRule{Presence: Required, Values: []string{"USABA"}},
+		Bic:           FieldRule{Presence: Required},
+		AccountNumber: FieldRule{MinLength: 6, MaxLength: 17, Numeric: true},
+		Iban:          FieldRule{Presence: Forbidden},
+	},
+}
+
+// RulesForCountry returns the rules of the given ISO 3166-1 alpha-2 country, or DefaultCountryRules
+// if the country has no specific rules.
+func RulesForCountry(country string) CountryRules {
+	if rules, ok := countryRules[country]; ok {
+		return rules
+	}
+	return DefaultCountryRules
+}
+
+// BankIDRule returns the rule of the bank ID of an account with the given account number.
+func (r CountryRules) BankIDRule(accountNumber string) FieldRule {
+	if accountNumber == "" && r.BankIDWithoutAccountNumber != nil {
+		return *r.BankIDWithoutAccountNumber
+	}
+	return r.BankID
+}
+
+// Tags of the validation errors reported when a field breaks the rules of its country.
+const (
+	TagCountryRequired      = "country_required"
+	TagCountryForbidden     = "country_forbidden"
+	TagCountryOneOf         = "country_oneof"
+	TagCountryLength        = "country_len"
+	TagCountryNumeric       = "country_numeric"
+	TagCountryStartsWith    = "country_startswith"
+	TagCountryStartsNotWith = "country_startsnotwith"
+)
+
+// Check returns the tag and parameter of the first rule value breaks, or an empty tag if it follows the rule.
+// country is used as the parameter of the presence and numeric rules, the prefix for the prefix rules.
+func (r FieldRule) Check(value, country string) (tag, param string) {
+	if value == "" {
+		if r.Presence == Required {
+			return TagCountryRequired, country
+		}
+		return "", ""
+	}
+	if r.Presence == Forbidden {
+		return TagCountryForbidden, country
+	}
+	if len(r.Values) > 0 && !slices.Contains(r.Values, value) {
+		return TagCountryOneOf, strings.Join(r.Values, " ")
+	}
+	if (r.MinLength > 0 && len(value) < r.MinLength) || (r.MaxLength > 0 && len(value) > r.MaxLength) {
+		return TagCountryLength, r.lengthParam()
+	}
+	if r.Numeric && !isDigits(value) {
+		return TagCountryNumeric, country
+	}
+	if r.StartsWith != "" && !strings.HasPrefix(value, r.StartsWith) {
+		return TagCountryStartsWith, r.StartsWith
+	}
+	if r.StartsNotWith != "" && strings.HasPrefix(value, r.StartsNotWith) {
+		return TagCountryStartsNotWith, r.StartsNotWith
+	}
+	return "", ""
+}
+
+// lengthParam describes the accepted lengths, like "8" or "6-10".
+func (r FieldRule) lengthParam() string {
+	if r.MinLength == r.MaxLength {
+		return strconv.Itoa(r.MinLength)
+	}
+	if r.MaxLength == 0 {
+		return strconv.Itoa(r.MinLength) + "-"
+	}
+	return strconv.Itoa(r.MinLength) + "-" + strconv.Itoa(r.MaxLength)
+}
+
+func isDigits(s string) bool {
+	for _, char := range s {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// countryAccount is a valid account of a country, alongside changes breaking each of its rules.
+type countryAccount struct {
+	bankID, bankIDCode, bic, accountNumber, iban string
+	violations                                   []countryViolation
+}
+
+// countryViolation is a change of a valid account breaking one rule of its country.
+type countryViolation struct {
+	invalidate                 func(attributes *models.AccountAttributes)
+	expectedField, expectedTag string
+}
+
+var countryAccounts = map[string]countryAccount{
+	"GB": {"400300", "GBDSC", "NWBKGB22", "41920716", "GB33BUKB20201555555555", []countryViolation{
+		{func(a *models.AccountAttributes) { a.AccountNumber = "1234567890" }, "AccountNumber", TagCountryLength},
+	}},
+	"AU": {"013012", "AUBSB", "NATAAU33", "123456789", "", []countryViolation{
+		{func(a *models.AccountAttributes) { a.Iban = "GB33BUKB20201555555555" }, "Iban", TagCountryForbidden},
+		{func(a *models.AccountAttributes) { a.AccountNumber = "023456789" }, "AccountNumber", TagCountryStartsNotWith},
+	}},
+	"BE": {"539", "BE", "", "0075470", "BE68539007547034", []countryViolation{
+		{func(a *models.AccountAttributes) { a.BankIDCode = "BEBAC" }, "BankIDCode", TagCountryOneOf},
+	}},
+	"CA": {"000300002", "CACPA", "ROYCCAT2", "1234567", "", []countryViolation{
+		{func(a *models.AccountAttributes) { a.Bic = "" }, "Bic", TagCountryRequired},
+		{func(a *models.AccountAttributes) { a.BankID = "100300002" }, "BankID", TagCountryStartsWith},
+	}},
+	"FR": {"2004101005", "FR", "", "0500013M02", "FR1420041010050500013M02606", []countryViolation{
+		{func(a *models.AccountAttributes) { a.BankID = "" }, "BankID", TagCountryRequired},
+	}},
+	"DE": {"37040044", "DEBLZ", "", "0532013", "DE89370400440532013000", []countryViolation{
+		{func(a *models.AccountAttributes) { a.BankID = "3704004A" }, "BankID", TagCountryNumeric},
+	}},
+	"GR": {"0110125", "GRBIC", "", "0000000012300695", "", []countryViolation{
+		{func(a *models.AccountAttributes) { a.AccountNumber = "12300695" }, "AccountNumber", TagCountryLength},
+	}},
+	"HK": {"004", "HKNCC", "HSBCHKHH", "123456789", "", []countryViolation{
+		{func(a *models.AccountAttributes) { a.AccountNumber = "12345678" }, "AccountNumber", TagCountryLength},
+		{func(a *models.AccountAttributes) { a.BankIDCode = "HKBNK" }, "BankIDCode", TagCountryOneOf},
+	}},
+	"IT": {"0542811101", "ITNCC", "", "000000123456", "IT60X0542811101000000123456", []countryViolation{
+		{func(a *models.AccountAttributes) { a.BankIDCode = "" }, "BankIDCode", TagCountryRequired},
+		{func(a *models.AccountAttributes) { a.BankID = "X0542811101" }, "BankID", TagCountryLength},
+		{func(a *models.AccountAttributes) { a.AccountNumber = "" }, "BankID", TagCountryLength},
+	}},
+	"LU": {"001", "LULUX", "", "9400644750000", "LU280019400644750000", []countryViolation{
+		{func(a *models.AccountAttributes) { a.BankID = "0019" }, "BankID", TagCountryLength},
+	}},
+	"NL": {"", "", "ABNANL2A", "0417164300", "NL91ABNA0417164300", []countryViolation{
+		{func(a *models.AccountAttributes) { a.BankID = "ABNA" }, "BankID", TagCountryForbidden},
+	}},
+	"PL": {"10901014", "PLKNR", "", "0000071219812874", "PL61109010140000071219812874", []countryViolation{
+		{func(a *models.AccountAttributes) { a.BankIDCode = "PLNCC" }, "BankIDCode", TagCountryOneOf},
+	}},
+	"PT": {"00020123", "PTNCC", "", "12345678901", "", []countryViolation{
+		{func(a *models.AccountAttributes) { a.AccountNumber = "1234567890A" }, "AccountNumber", TagCountryNumeric},
+	}},
+	"ES": {"21000418", "ESNCC", "", "0200051332", "ES9121000418450200051332", []countryViolation{
+		{func(a *models.AccountAttributes) { a.BankID = "2100" }, "BankID", TagCountryLength},
+	}},
+	"CH": {"00762", "CHBCC", "", "011623852957", "CH9300762011623852957", []countryViolation{
+		{func(a *models.AccountAttributes) { a.BankIDCode = "" }, "BankIDCode", TagCountryRequired},
+	}},
+	"US": {"021000021", "USABA", "CHASUS33", "123456789", "", []countryViolation{
+		{func(a *models.AccountAttributes) { a.Bic = "" }, "Bic", TagCountryRequired},
+	}},
+}
+
+func (c countryAccount) attributes(country string) models.AccountAttributes {
+	return models.AccountAttributes{
+		AccountNumber: c.accountNumber,
+		BankID:        c.bankID,
+		BankIDCode:    c.bankIDCode,
+		BaseCurrency:  "EUR",
+		Bic:           c.bic,
+		Country:       &country,
+		Iban:          c.iban,
+		Name:          []string{"Batman"},
+	}
+}
+
+func TestCountryRules_EveryCountryIsTested(t *testing.T) {
+	for country := range countryRules {
+		assert.Contains(t, countryAccounts, country)
+	}
+}
+
+func TestCountryRules_ValidAccountsPass(t *testing.T) {
+	validate := New()
+	for country, account := range countryAccounts {
+		assert.Nil(t, validate.Struct(account.attributes(country)), country)
+	}
+}
+
+func TestCountryRules_InvalidAccountsReturnBrokenRule(t *testing.T) {
+	validate := New()
+	for country, account := range countryAccounts {
+		for _, violation := range account.violations {
+			attributes := account.attributes(country)
+			violation.invalidate(&attributes)
+
+			err := validate.Struct(attributes)
+			var validationErrors validator.ValidationErrors
+			if assert.ErrorAs(t, err, &validationErrors, country) && assert.Len(t, validationErrors, 1, country) {
+				assert.Equal(t, violation.expectedField, validationErrors[0].StructField(), country)
+				assert.Equal(t, violation.expectedTag, validationErrors[0].Tag(), country)
+			}
+		}
+	}
+}
+
+func TestCountryRules_OptionalFieldsCanBeLeftOut(t *testing.T) {
+	validate := New()
+
+	hongKong := countryAccounts["HK"].attributes("HK")
+	hongKong.BankIDCode = ""
+	assert.Nil(t, validate.Struct(hongKong))
+
+	italy := countryAccounts["IT"].attributes("IT")
+	italy.BankID = "X0542811101"
+	italy.AccountNumber = ""
+	assert.Nil(t, validate.Struct(italy))
+}
+
+func TestCountryRules_UnknownCountryUsesDefaultRules(t *testing.T) {
+	assert.Equal(t, DefaultCountryRules, RulesForCountry("AR"))
+
+	tag, param := RulesForCountry("AR").BankID.Check("", "AR")
+	assert.Equal(t, TagCountryRequired, tag)
+	assert.Equal(t, "AR", param)
+}
+
+func TestFieldRule_CheckReturnsParamOfBrokenRule(t *testing.T) {
+	rule := FieldRule{MinLength: 6, MaxLength: 10, Numeric: true}
+	tag, param := rule.Check("12345", "AU")
+	assert.Equal(t, TagCountryLength, tag)
+	assert.Equal(t, "6-10", param)
+
+	rule = FieldRule{Presence: Required, Values: []string{"GBDSC"}}
+	tag, param = rule.Check("AUBSB", "GB")
+	assert.Equal(t, TagCountryOneOf, tag)
+	assert.Equal(t, "GBDSC", param)
+}
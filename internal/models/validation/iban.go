@@ -0,0 +1,125 @@
+package validation
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Errors returned by ValidateIBAN, describing the first check the IBAN failed.
+var (
+	ErrIBANStructure   = errors.New("validation: IBAN must be a country code, 2 check digits and up to 30 uppercase letters or digits")
+	ErrIBANCountry     = errors.New("validation: IBAN country is not in the IBAN registry")
+	ErrIBANLength      = errors.New("validation: IBAN length does not match its country")
+	ErrIBANFormat      = errors.New("validation: IBAN account number (BBAN) does not match the format of its country")
+	ErrIBANCheckDigits = errors.New("validation: IBAN check digits are invalid")
+)
+
+//go:embed iban_registry.txt
+var ibanRegistryFile string
+
+// ibanCountry is the IBAN structure of a country in the registry.
+type ibanCountry struct {
+	length int
+	bban   *regexp.Regexp
+}
+
+var (
+	ibanRegistry  = parseIBANRegistry(ibanRegistryFile)
+	ibanStructure = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{1,30}$`)
+	bbanPart      = regexp.MustCompile(`([0-9]+)(!?)([nac])`)
+)
+
+// ValidateIBAN checks that iban is an IBAN in electronic format (uppercase, no spaces), following ISO 13616: its
+// length and account number (BBAN) must match the format registered for its country and its check digits must be
+// between 02 and 98 and pass the mod-97 check. The returned error is one of the ErrIBAN errors.
+func ValidateIBAN(iban string) error {
+	if !ibanStructure.MatchString(iban) {
+		return ErrIBANStructure
+	}
+	country, ok := ibanRegistry[iban[:2]]
+	if !ok {
+		return ErrIBANCountry
+	}
+	if len(iban) != country.length {
+		return ErrIBANLength
+	}
+	if !country.bban.MatchString(iban[4:]) {
+		return ErrIBANFormat
+	}
+	if checkDigits := iban[2:4]; checkDigits < "02" || checkDigits > "98" || ibanMod97(iban) != 1 {
+		return ErrIBANCheckDigits
+	}
+	return nil
+}
+
+// ibanMod97 moves the country code and check digits of the IBAN to its end, replaces every letter by two digits
+// (A = 10, ..., Z = 35) and returns the remainder of the resulting number divided by 97.
+func ibanMod97(iban string) int {
+	remainder := 0
+	for _, char := range iban[4:] + iban[:4] {
+		switch {
+		case char >= '0' && char <= '9':
+			remainder = (remainder*10 + int(char-'0')) % 97
+		case char >= 'A' && char <= 'Z':
+			remainder = (remainder*100 + int(char-'A') + 10) % 97
+		}
+	}
+	return remainder
+}
+
+// parseIBANRegistry parses the embedded registry. Since the registry is part of the binary, malformed lines panic.
+func parseIBANRegistry(registry string) map[string]ibanCountry {
+	countries := make(map[string]ibanCountry)
+	for _, line := range strings.Split(registry, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			panic("validation: malformed IBAN registry line: " + line)
+		}
+		length, err := strconv.Atoi(fields[1])
+		if err != nil {
+			panic("validation: malformed IBAN registry line: " + line)
+		}
+		bban, bbanLength := bbanRegexp(fields[2])
+		if 4+bbanLength != length {
+			panic(fmt.Sprintf("validation: IBAN registry length of %s does not match its BBAN format", fields[0]))
+		}
+		countries[fields[0]] = ibanCountry{length: length, bban: bban}
+	}
+	return countries
+}
+
+// bbanRegexp converts a BBAN format of the registry, like "4!a6!n8!n", into a regular expression.
+// It also returns the maximum length of the BBAN.
+func bbanRegexp(format string) (*regexp.Regexp, int) {
+	if bbanPart.ReplaceAllString(format, "") != "" {
+		panic("validation: malformed IBAN registry BBAN format: " + format)
+	}
+	var expression strings.Builder
+	length := 0
+	for _, part := range bbanPart.FindAllStringSubmatch(format, -1) {
+		partLength, _ := strconv.Atoi(part[1])
+		length += partLength
+		switch part[3] {
+		case "n":
+			expression.WriteString("[0-9]")
+		case "a":
+			expression.WriteString("[A-Z]")
+		case "c":
+			expression.WriteString("[A-Z0-9]")
+		}
+		if part[2] == "!" {
+			expression.WriteString("{" + part[1] + "}")
+		} else {
+			expression.WriteString("{1," + part[1] + "}")
+		}
+	}
+	return regexp.MustCompile("^" + expression.String() + "$"), length
+}
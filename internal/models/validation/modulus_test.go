@@ -0,0 +1,189 @@
+package validation
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+// modulusTestVectors are the test cases published in the Vocalink modulus checking specification.
+var modulusTestVectors = []struct {
+	sortCode, accountNumber string
+	valid                   bool
+	description             string
+}{
+	{"089999", "66374958", true, "pass modulus 10 check"},
+	{"107999", "88837491", true, "pass modulus 11 check"},
+	{"202959", "63748472", true, "pass modulus 11 and double alternate checks"},
+	{"871427", "46238510", true, "exception 10 & 11 where first check passes and second check fails"},
+	{"872427", "46238510", true, "exception 10 & 11 where first check fails and second check passes"},
+	{"871427", "09123496", true, "exception 10 where in the account number ab=09 and the g=9"},
+	{"871427", "99123496", true, "exception 10 where in the account number ab=99 and the g=9"},
+	{"820000", "73688637", true, "exception 3, sorting code is the start of a range and c=6"},
+	{"827999", "73988638", true, "exception 3, sorting code is the end of a range and c=9"},
+	{"827101", "28748352", true, "exception 3 where c is not 6 nor 9 and both checks pass"},
+	{"134020", "63849203", true, "exception 4 where the remainder is equal to the checkdigit"},
+	{"118765", "64371389", true, "exception 1 where 27 is added to the total"},
+	{"200915", "41011166", true, "exception 6 where the account fails standard check but is a foreign currency account"},
+	{"938611", "07806039", true, "exception 5 where the check passes"},
+	{"938600", "42368003", true, "exception 5 where the check passes with substitution"},
+	{"938063", "55065200", true, "exception 5 where both checks produce a remainder of 0"},
+	{"772798", "99345694", true, "exception 7 where passes but would fail the standard check"},
+	{"086090", "06774744", true, "exception 8 where the check passes"},
+	{"309070", "02355688", true, "exception 2 & 9 where the first check passes"},
+	{"309070", "12345668", true, "exception 2 & 9 where the first check fails and second check passes with substitution"},
+	{"309070", "12345677", true, "exception 2 & 9 where a is not 0 and g is not 9"},
+	{"309070", "99345694", true, "exception 2 & 9 where a is not 0 and g=9"},
+	{"938063", "15764273", false, "exception 5 where the first checkdigit is correct and the second incorrect"},
+	{"938063", "15764264", false, "exception 5 where the first checkdigit is incorrect and the second correct"},
+	{"938063", "15763217", false, "exception 5 where the first checkdigit is incorrect with a remainder of 1"},
+	{"118765", "64371388", false, "exception 1 where it fails double alternate check"},
+	{"203099", "66831036", false, "pass modulus 11 check and fail double alternate check"},
+	{"203099", "58716970", false, "fail modulus 11 check and pass double alternate check"},
+	{"089999", "66374959", false, "fail modulus 10 check"},
+	{"107999", "88837493", false, "fail modulus 11 check"},
+	{"074456", "12345112", true, "exception 12/13 where passes modulus 11 check"},
+	{"070116", "34012583", true, "exception 12/13 where passes modulus 11 check"},
+	{"074456", "11104102", true, "exception 12/13 where fails the modulus 11 check, but passes the modulus 10 check"},
+	{"180002", "00000190", true, "exception 14 where the first check fails and the second check passes"},
+}
+
+// TestModulusChecker_PublishedTestVectors runs every test vector in its own subtest. The vectors of sort codes that
+// are not in the embedded extract are skipped: set MODULUS_WEIGHTS_FILE and MODULUS_SUBSTITUTIONS_FILE to the
+// valacdos.txt and scsubtab.txt files published by Vocalink to run all of them.
+func TestModulusChecker_PublishedTestVectors(t *testing.T) {
+	checker := embeddedModulusChecker
+	if weightsFile, substitutionsFile := os.Getenv("MODULUS_WEIGHTS_FILE"), os.Getenv("MODULUS_SUBSTITUTIONS_FILE"); weightsFile != "" {
+		weights, err := os.Open(weightsFile)
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer weights.Close()
+		substitutions, err := os.Open(substitutionsFile)
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer substitutions.Close()
+		checker, err = NewModulusChecker(weights, substitutions)
+		if !assert.Nil(t, err) {
+			return
+		}
+	}
+
+	for _, vector := range modulusTestVectors {
+		t.Run(vector.sortCode+"-"+vector.accountNumber, func(t *testing.T) {
+			if !checker.Covers(vector.sortCode) {
+				t.Skipf("sort code %s is not in the modulus weight table, load the Vocalink tables to run %q", vector.sortCode, vector.description)
+			}
+			err := checker.Check(vector.sortCode, vector.accountNumber)
+			if vector.valid {
+				assert.Nil(t, err, vector.description)
+			} else {
+				assert.ErrorIs(t, err, ErrModulusCheck, vector.description)
+			}
+		})
+	}
+}
+
+// exceptionsTable contains rows written for TestModulusChecker_Exceptions, not taken from the Vocalink table,
+// to run every exception even when the embedded extract has no row using it.
+const exceptionsTable = `
+100001 100001 DBLAL    0    0    0    0    0    0    2    1    2    1    2    1    2    1    1
+100002 100002 MOD11    0    0    0    0    0    0    8    7    6    5    4    3    2    1    2
+100002 100002 MOD11    7    6    5    4    3    2    8    7    6    5    4    3    2    1    9
+100003 100003 MOD11    0    0    0    0    0    0    8    7    6    5    4    3    2    1    3
+100003 100003 DBLAL    2    1    2    1    2    1    2    1    2    1    2    1    2    1    3
+100004 100004 MOD11    0    0    0    0    0    0    8    7    6    5    4    3    2    1    6
+100004 100004 DBLAL    2    1    2    1    2    1    2    1    2    1    2    1    2    1    6
+100005 100005 MOD11    1    2    3    4    5    6    8    7    6    5    4    3    2    1    7
+100006 100006 MOD11    7    6    5    4    3    2    8    7    6    5    4    3    2    1    8
+100007 100007 MOD11    1    2    3    4    5    6    8    7    6    5    4    3    2    1   10
+100007 100007 MOD10    0    0    0    0    0    0    7    1    3    7    1    3    7    1   11
+100008 100008 MOD11    0    0    0    0    0    0    8    7    6    5    4    3    2    1   12
+100008 100008 MOD10    0    0    0    0    0    0    7    1    3    7    1    3    7    1   13
+100009 100009 MOD11    0    0    0    0    0    0    8    7    6    5    4    3    2    1   14
+`
+
+func TestModulusChecker_Exceptions(t *testing.T) {
+	checker, err := NewModulusChecker(strings.NewReader(exceptionsTable), strings.NewReader(""))
+	if !assert.Nil(t, err) {
+		return
+	}
+	tests := []struct {
+		sortCode, accountNumber string
+		valid                   bool
+		description             string
+	}{
+		{"100001", "06480894", true, "exception 1 adds 27 to the total"},
+		{"100001", "09722233", false, "exception 1 fails"},
+		{"100002", "73517017", true, "exception 2 substitutes weights when a is not 0 and g is not 9"},
+		{"100002", "26752197", true, "exception 2 substitutes other weights when a is not 0 and g is 9"},
+		{"100002", "53128543", true, "exception 9 checks again with sort code 309634"},
+		{"100002", "53873226", false, "exceptions 2 and 9 fail"},
+		{"100003", "88618129", true, "exception 3 skips the second check when c is 6 or 9"},
+		{"100003", "94801142", false, "exception 3 runs the second check otherwise"},
+		{"100004", "57917877", true, "exception 6 does not check foreign currency accounts"},
+		{"100004", "69203339", false, "exception 6 checks other accounts"},
+		{"100005", "69448796", true, "exception 7 zeroises weights u to b when g is 9"},
+		{"100005", "83742074", false, "exception 7 keeps weights when g is not 9"},
+		{"100006", "08628964", true, "exception 8 checks with sort code 090126"},
+		{"100007", "09485098", true, "exception 10 zeroises weights u to b when ab is 09 and g is 9"},
+		{"100007", "03638903", true, "exceptions 10 and 11 pass when the second check passes"},
+		{"100007", "29519537", false, "exceptions 10 and 11 fail"},
+		{"100008", "21880344", true, "exceptions 12 and 13 pass when the first check passes"},
+		{"100008", "46401029", true, "exceptions 12 and 13 pass when the second check passes"},
+		{"100008", "21093176", false, "exceptions 12 and 13 fail"},
+		{"100009", "75108459", true, "exception 14 shifts the account number when h is 0, 1 or 9"},
+		{"100009", "94279884", false, "exception 14 does not shift other account numbers"},
+	}
+	for _, test := range tests {
+		t.Run(test.sortCode+"-"+test.accountNumber, func(t *testing.T) {
+			err := checker.Check(test.sortCode, test.accountNumber)
+			if test.valid {
+				assert.Nil(t, err, test.description)
+			} else {
+				assert.ErrorIs(t, err, ErrModulusCheck, test.description)
+			}
+		})
+	}
+}
+
+func TestModulusChecker_UncoveredSortCodeIsValid(t *testing.T) {
+	assert.False(t, embeddedModulusChecker.Covers("400300"))
+	assert.Nil(t, CheckUKAccountNumber("400300", "12345678"))
+}
+
+func TestModulusChecker_InvalidFormatsReturnFormatError(t *testing.T) {
+	assert.ErrorIs(t, CheckUKAccountNumber("40030", "12345678"), ErrSortCodeFormat)
+	assert.ErrorIs(t, CheckUKAccountNumber("40-03-00", "12345678"), ErrSortCodeFormat)
+	assert.ErrorIs(t, CheckUKAccountNumber("400300", "1234567"), ErrAccountNumberFormat)
+	assert.ErrorIs(t, CheckUKAccountNumber("400300", "1234567A"), ErrAccountNumberFormat)
+}
+
+func TestNewModulusChecker_InvalidTablesReturnError(t *testing.T) {
+	_, err := NewModulusChecker(strings.NewReader("089000 089999 MOD12 0 0 0 0 0 0 7 1 3 7 1 3 7 1"), strings.NewReader(""))
+	assert.EqualError(t, err, "validation: invalid modulus weight table: line 1: unknown method MOD12")
+
+	_, err = NewModulusChecker(strings.NewReader(""), strings.NewReader("938600"))
+	assert.EqualError(t, err, "validation: invalid sort code substitution table: line 1: expected original and substitute sort codes")
+}
+
+func TestUseModulusChecker_ReplacesTablesOfValidator(t *testing.T) {
+	checker, err := NewModulusChecker(strings.NewReader("400300 400300 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1"), strings.NewReader(""))
+	if !assert.Nil(t, err) {
+		return
+	}
+	UseModulusChecker(checker)
+	defer UseModulusChecker(nil)
+
+	country := "GB"
+	attributes := countryAccounts["GB"].attributes(country)
+	attributes.AccountNumber = "12345678"
+	err = New().Struct(attributes)
+	assert.ErrorContains(t, err, TagUKModulus)
+
+	// 8*1 + 7*2 + 6*3 + 5*4 + 4*5 + 3*6 + 2*7 + 1*9 = 121 = 11*11
+	attributes.AccountNumber = "12345679"
+	assert.Nil(t, New().Struct(attributes))
+}
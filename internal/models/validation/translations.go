@@ -0,0 +1,150 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	"strings"
+)
+
+// Locales of the validation messages. English is used for any other locale.
+const (
+	English = "en"
+	Spanish = "es"
+	French  = "fr"
+)
+
+// messages are the translations of the rules that go-playground/validator does not translate: the ones of this
+// package and the ISO rules used by the models. {0} is replaced by the JSON name of the field, {1} by the
+// parameter of the rule and {2} by the rejected value.
+var messages = map[string]map[string]string{
+	English: {
+		"iso4217":               "{0} must be a valid ISO 4217 currency code",
+		"iso3166_1_alpha2":      "{0} must be a valid ISO 3166-1 alpha-2 country code",
+		"iban":                  "{0} must be a valid IBAN",
+		"bic_length":            "{0} must be 8 or 11 characters long",
+		"bic_institution":       "{0} must start with a 4 letters institution code",
+		"bic_country":           "{0} must contain a valid country code after the institution code",
+		"bic_location":          "{0} must contain a 2 letters or digits location code after the country code",
+		"bic_branch":            "{0} must end with a 3 letters or digits branch code",
+		"bic_matches_country":   "{0} must belong to a bank of {1}",
+		TagCountryRequired:      "{0} is required for accounts in {1}",
+		TagCountryForbidden:     "{0} is not allowed for accounts in {1}",
+		TagCountryOneOf:         "{0} must be one of [{1}]",
+		TagCountryLength:        "{0} must be {1} characters long",
+		TagCountryNumeric:       "{0} must only contain digits for accounts in {1}",
+		TagCountryStartsWith:    "{0} must start with {1}",
+		TagCountryStartsNotWith: "{0} cannot start with {1}",
+		TagUKModulus:            "{0} is not a valid account number for sort code {1}",
+		TagStatusTransition:     "{0} cannot change from {1} to {2}",
+	},
+	Spanish: {
+		"iso4217":               "{0} debe ser un código de moneda ISO 4217 válido",
+		"iso3166_1_alpha2":      "{0} debe ser un código de país ISO 3166-1 alfa-2 válido",
+		"iban":                  "{0} debe ser un IBAN válido",
+		"bic_length":            "{0} debe tener 8 u 11 caracteres",
+		"bic_institution":       "{0} debe comenzar con un código de entidad de 4 letras",
+		"bic_country":           "{0} debe contener un código de país válido después del código de entidad",
+		"bic_location":          "{0} debe contener un código de localidad de 2 letras o dígitos después del código de país",
+		"bic_branch":            "{0} debe terminar con un código de sucursal de 3 letras o dígitos",
+		"bic_matches_country":   "{0} debe pertenecer a un banco de {1}",
+		TagCountryRequired:      "{0} es obligatorio para las cuentas de {1}",
+		TagCountryForbidden:     "{0} no está permitido para las cuentas de {1}",
+		TagCountryOneOf:         "{0} debe ser uno de [{1}]",
+		TagCountryLength:        "{0} debe tener {1} caracteres",
+		TagCountryNumeric:       "{0} solo debe contener dígitos para las cuentas de {1}",
+		TagCountryStartsWith:    "{0} debe comenzar con {1}",
+		TagCountryStartsNotWith: "{0} no puede comenzar con {1}",
+		TagUKModulus:            "{0} no es un número de cuenta válido para el sort code {1}",
+		TagStatusTransition:     "{0} no puede cambiar de {1} a {2}",
+	},
+	French: {
+		"iso4217":               "{0} doit être un code de devise ISO 4217 valide",
+		"iso3166_1_alpha2":      "{0} doit être un code pays ISO 3166-1 alpha-2 valide",
+		"iban":                  "{0} doit être un IBAN valide",
+		"bic_length":            "{0} doit contenir 8 ou 11 caractères",
+		"bic_institution":       "{0} doit commencer par un code banque de 4 lettres",
+		"bic_country":           "{0} doit contenir un code pays valide après le code banque",
+		"bic_location":          "{0} doit contenir un code emplacement de 2 lettres ou chiffres après le code pays",
+		"bic_branch":            "{0} doit se terminer par un code branche de 3 lettres ou chiffres",
+		"bic_matches_country":   "{0} doit appartenir à une banque de {1}",
+		TagCountryRequired:      "{0} est obligatoire pour les comptes de {1}",
+		TagCountryForbidden:     "{0} n'est pas autorisé pour les comptes de {1}",
+		TagCountryOneOf:         "{0} doit être l'une des valeurs [{1}]",
+		TagCountryLength:        "{0} doit contenir {1} caractères",
+		TagCountryNumeric:       "{0} ne doit contenir que des chiffres pour les comptes de {1}",
+		TagCountryStartsWith:    "{0} doit commencer par {1}",
+		TagCountryStartsNotWith: "{0} ne peut pas commencer par {1}",
+		TagUKModulus:            "{0} n'est pas un numéro de compte valide pour le sort code {1}",
+		TagStatusTransition:     "{0} ne peut pas passer de {1} à {2}",
+	},
+}
+
+// defaultTranslations register the messages of go-playground/validator for each locale.
+var defaultTranslations = map[string]func(*validator.Validate, ut.Translator) error{
+	English: en_translations.RegisterDefaultTranslations,
+	Spanish: es_translations.RegisterDefaultTranslations,
+	French:  fr_translations.RegisterDefaultTranslations,
+}
+
+// Translations contains the messages of the validation errors of a validator in every supported locale.
+type Translations struct {
+	universal *ut.UniversalTranslator
+}
+
+// RegisterTranslations registers on validate, which must have been built with New, the messages of every rule
+// used by the models and this package in English, Spanish and French.
+func RegisterTranslations(validate *validator.Validate) (*Translations, error) {
+	english := en.New()
+	universal := ut.New(english, english, es.New(), fr.New())
+	for locale, registerDefaultTranslations := range defaultTranslations {
+		translator, _ := universal.GetTranslator(locale)
+		if err := registerDefaultTranslations(validate, translator); err != nil {
+			return nil, err
+		}
+		for tag, message := range messages[locale] {
+			if err := validate.RegisterTranslation(tag, translator, addMessage(tag, message), translate); err != nil {
+				return nil, err
+			}
+		}
+		// The errors of the bic alias are translated with the message of the part of the BIC that is invalid.
+		if err := validate.RegisterTranslation("bic", translator, noMessage, translate); err != nil {
+			return nil, err
+		}
+	}
+	return &Translations{universal: universal}, nil
+}
+
+// Translator returns the translator of the given locale, like "es" or "fr-CA", or the English one if the locale
+// is not supported.
+func (t *Translations) Translator(locale string) ut.Translator {
+	locale = strings.ReplaceAll(strings.ToLower(locale), "-", "_")
+	language, _, _ := strings.Cut(locale, "_")
+	translator, _ := t.universal.FindTranslator(locale, language)
+	return translator
+}
+
+func addMessage(tag, message string) validator.RegisterTranslationsFunc {
+	return func(translator ut.Translator) error {
+		return translator.Add(tag, message, true)
+	}
+}
+
+func noMessage(ut.Translator) error {
+	return nil
+}
+
+// translate returns the message of the actual tag of the error, so aliases use the message of the failed rule.
+func translate(translator ut.Translator, fieldError validator.FieldError) string {
+	message, err := translator.T(fieldError.ActualTag(), fieldError.Field(), fieldError.Param(), fmt.Sprint(fieldError.Value()))
+	if err != nil {
+		return fieldError.Error()
+	}
+	return message
+}
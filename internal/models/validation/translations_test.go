@@ -0,0 +1,91 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestMessages_EveryLocaleTranslatesTheSameRules(t *testing.T) {
+	for locale := range defaultTranslations {
+		for tag := range messages[English] {
+			assert.Contains(t, messages[locale], tag, locale)
+		}
+		assert.Len(t, messages[locale], len(messages[English]), locale)
+	}
+}
+
+func TestRegisterTranslations_EveryRuleOfTheModelsIsTranslated(t *testing.T) {
+	validate := New()
+	translations, err := RegisterTranslations(validate)
+	if !assert.Nil(t, err) {
+		return
+	}
+	// Account breaking every rule of the models.
+	country := "ZZ"
+	var version int64 = -1
+	account := models.Account{Data: &models.AccountData{
+		Attributes: &models.AccountAttributes{
+			AccountNumber:           "1234-5678",
+			AlternativeNames:        []string{""},
+			BankID:                  "4003!",
+			BankIDCode:              strings.Repeat("A", 17),
+			BaseCurrency:            "XXX",
+			Bic:                     "NWBK",
+			Country:                 &country,
+			Iban:                    "GB00",
+			Name:                    []string{"Batman", "Bruce", "Wayne", "Bat", "Man"},
+			SecondaryIdentification: strings.Repeat("A", 141),
+		},
+		ID:      "not-uuid",
+		Version: &version,
+	}}
+
+	err = validate.Struct(account)
+	var validationErrors validator.ValidationErrors
+	if assert.ErrorAs(t, err, &validationErrors) {
+		for locale := range defaultTranslations {
+			for _, fieldError := range validationErrors {
+				message := fieldError.Translate(translations.Translator(locale))
+				assert.NotContains(t, message, "Key: ", "%s: %s", locale, fieldError.ActualTag())
+				assert.Contains(t, message, fieldError.Field(), "%s: %s", locale, fieldError.ActualTag())
+			}
+		}
+	}
+}
+
+func TestRegisterTranslations_EveryCountryRuleIsTranslated(t *testing.T) {
+	validate := New()
+	translations, err := RegisterTranslations(validate)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for country, countryAccount := range countryAccounts {
+		for _, violation := range countryAccount.violations {
+			attributes := countryAccount.attributes(country)
+			violation.invalidate(&attributes)
+
+			err := validate.Struct(attributes)
+			var validationErrors validator.ValidationErrors
+			if assert.ErrorAs(t, err, &validationErrors, country) {
+				for locale := range defaultTranslations {
+					message := validationErrors[0].Translate(translations.Translator(locale))
+					assert.NotContains(t, message, "Key: ", "%s: %s", country, locale)
+				}
+			}
+		}
+	}
+}
+
+func TestTranslations_TranslatorFallsBackToLanguageThenEnglish(t *testing.T) {
+	translations, err := RegisterTranslations(New())
+	if assert.Nil(t, err) {
+		assert.Equal(t, Spanish, translations.Translator("es").Locale())
+		assert.Equal(t, French, translations.Translator("fr-CA").Locale())
+		assert.Equal(t, French, translations.Translator("FR_fr").Locale())
+		assert.Equal(t, English, translations.Translator("de").Locale())
+		assert.Equal(t, English, translations.Translator("").Locale())
+	}
+}
@@ -0,0 +1,123 @@
+// Package validation contains the validations of account fields that go beyond the ones provided
+// by go-playground/validator, like IBAN check digits.
+package validation
+
+import (
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/nambroa/interview-accountapi/internal/models"
+	"reflect"
+	"strings"
+)
+
+// New returns a validator with the validations of this package registered under their tags:
+//   - iban: the field is an IBAN in electronic format, see ValidateIBAN.
+//   - bic: the field is a BIC, see ParseBIC. It is an alias of bic_length, bic_institution, bic_country,
+//     bic_location and bic_branch, so the ActualTag of a validation error tells which part is invalid.
+//
+// Field names in validation errors are the JSON names of the fields, for example "bank_id",
+// so the namespace of an error is the JSON path of the field after the name of the validated struct.
+//
+// It also checks that the attributes of models.AccountAttributes follow the rules of their Country (see RulesForCountry),
+// reporting one of the TagCountry tags otherwise, and that their BIC agrees with their Country, reporting bic_matches_country otherwise.
+// The account number of accounts identified by a UK sort code (GBDSC) must pass the modulus checks of the sort code,
+// see CheckUKAccountNumber, reporting TagUKModulus otherwise. These rules involving several attributes are skipped
+// when validating with a context returned by FieldsOnly. When validating with a context returned by WithCurrentStatus,
+// their Status must be allowed from the current status by models.AllowedTransition, reporting TagStatusTransition otherwise.
+func New() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	// Registration only fails for empty tags or nil functions.
+	_ = validate.RegisterValidation("iban", func(fl validator.FieldLevel) bool {
+		return ValidateIBAN(fl.Field().String()) == nil
+	})
+	bicTags := make([]string, 0, len(bicChecks))
+	for _, check := range bicChecks {
+		valid := check.valid
+		_ = validate.RegisterValidation(check.tag, func(fl validator.FieldLevel) bool {
+			return valid(fl.Field().String())
+		})
+		bicTags = append(bicTags, check.tag)
+	}
+	validate.RegisterAlias("bic", strings.Join(bicTags, ","))
+	validate.RegisterStructValidationCtx(validateAccountAttributes, models.AccountAttributes{})
+	return validate
+}
+
+// TagUKModulus is the tag of the validation errors reported when a UK account number fails the modulus checks of its sort code.
+const TagUKModulus = "uk_modulus"
+
+// TagStatusTransition is the tag of the validation errors reported when the status of an account cannot change to
+// the validated one. The parameter of the errors is the current status.
+const TagStatusTransition = "status_transition"
+
+type currentStatusKey struct{}
+
+type fieldsOnlyKey struct{}
+
+// FieldsOnly returns a copy of ctx telling validators built with New to only check the rules of each field (their tags),
+// skipping the country rules, the UK modulus check and the BIC country check. It is meant for accounts that are
+// not sent to the API, like the ones it returns, which were valid under the rules in force when they were stored.
+func FieldsOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fieldsOnlyKey{}, true)
+}
+
+// WithCurrentStatus returns a copy of ctx holding the current status of the validated account, so validators built
+// with New check that the status can change to the one of the validated attributes.
+func WithCurrentStatus(ctx context.Context, status models.AccountStatus) context.Context {
+	return context.WithValue(ctx, currentStatusKey{}, status)
+}
+
+// validateAccountAttributes runs the validations involving several attributes of an account, or the attributes
+// and the current status of the account held by ctx.
+func validateAccountAttributes(ctx context.Context, sl validator.StructLevel) {
+	attributes := sl.Current().Interface().(models.AccountAttributes)
+	if currentStatus, ok := ctx.Value(currentStatusKey{}).(models.AccountStatus); ok && attributes.Status != nil {
+		if !models.AllowedTransition(currentStatus, *attributes.Status) {
+			sl.ReportError(*attributes.Status, "status", "Status", TagStatusTransition, string(currentStatus))
+		}
+	}
+	if fieldsOnly, _ := ctx.Value(fieldsOnlyKey{}).(bool); fieldsOnly || attributes.Country == nil {
+		return
+	}
+
+	country := *attributes.Country
+	rules := RulesForCountry(country)
+	fields := []struct {
+		name, structName string
+		value            string
+		rule             FieldRule
+	}{
+		{"bank_id", "BankID", attributes.BankID, rules.BankIDRule(attributes.AccountNumber)},
+		{"bank_id_code", "BankIDCode", attributes.BankIDCode, rules.BankIDCode},
+		{"bic", "Bic", attributes.Bic, rules.Bic},
+		{"account_number", "AccountNumber", attributes.AccountNumber, rules.AccountNumber},
+		{"iban", "Iban", attributes.Iban, rules.Iban},
+	}
+	for _, field := range fields {
+		if tag, param := field.rule.Check(field.value, country); tag != "" {
+			sl.ReportError(field.value, field.name, field.structName, tag, param)
+		}
+	}
+
+	if attributes.BankIDCode == "GBDSC" && attributes.AccountNumber != "" {
+		if err := CheckUKAccountNumber(attributes.BankID, attributes.AccountNumber); errors.Is(err, ErrModulusCheck) {
+			sl.ReportError(attributes.AccountNumber, "account_number", "AccountNumber", TagUKModulus, attributes.BankID)
+		}
+	}
+
+	if attributes.Bic == "" {
+		return
+	}
+	bic, err := ParseBIC(attributes.Bic)
+	if err == nil && !BICMatchesCountry(bic, country) {
+		sl.ReportError(attributes.Bic, "bic", "Bic", "bic_matches_country", country)
+	}
+}